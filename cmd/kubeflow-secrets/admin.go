@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// isAdminRequest reports whether the caller belongs to one of ADMIN_GROUPS.
+// Admin views still run through the caller's impersonated client, so RBAC
+// stays the final authority; the group check only unlocks the endpoints.
+func (s *server) isAdminRequest(r *http.Request) bool {
+	if len(s.adminGroups) == 0 {
+		return false
+	}
+
+	_, groups, err := s.identityFromRequest(r)
+	if err != nil {
+		return false
+	}
+	for _, group := range groups {
+		if _, ok := s.adminGroups[group]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *server) handleAdminMigrateLabels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.isAdminRequest(r) {
+		writeError(w, http.StatusForbidden, "admin group required")
+		return
+	}
+
+	userNamespace, impClient, ok := s.userContext(w, r)
+	if !ok {
+		return
+	}
+
+	from := s.legacyManagedValues
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		from = normalizeGroups([]string{raw})
+	}
+	if len(from) == 0 && legacyManagedByLabelKey == "" {
+		writeError(w, http.StatusBadRequest, "no legacy managed-by values configured or given via ?from=, and no legacy label key configured")
+		return
+	}
+	for _, value := range from {
+		if value == managedByLabelValue {
+			writeError(w, http.StatusBadRequest, "from must not include the current managed-by value")
+			return
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid from value %q", value))
+			return
+		}
+	}
+
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	// Without legacy values this is a key migration: stamp the new label
+	// key on secrets that only carry the legacy one.
+	selector := fmt.Sprintf("%s=%s,!%s", legacyManagedByLabelKey, managedByLabelValue, managedByLabelKey)
+	if len(from) > 0 {
+		selector = fmt.Sprintf("%s in (%s)", managedSelectorKey(), strings.Join(from, ","))
+	}
+	list, err := impClient.CoreV1().Secrets(userNamespace).List(r.Context(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to list secrets")
+		writeError(w, status, msg)
+		return
+	}
+
+	resp := migrateLabelsResponse{
+		Namespace:  userNamespace,
+		From:       from,
+		FromKey:    legacyManagedByLabelKey,
+		DryRun:     dryRun,
+		Matched:    len(list.Items),
+		bulkResult: newBulkResult(len(list.Items)),
+	}
+	for i := range list.Items {
+		secret := &list.Items[i]
+		if dryRun {
+			resp.add(bulkItemResult{Name: secret.Name, Status: http.StatusOK})
+			continue
+		}
+
+		secret.Labels = ensureManagedLabels(secret.Labels)
+		if _, err := impClient.CoreV1().Secrets(userNamespace).Update(r.Context(), secret, metav1.UpdateOptions{}); err != nil {
+			logSafef("label migration failed: namespace=%q name=%q err=%v", userNamespace, secret.Name, err)
+			status, msg := mapKubeError(err, "failed to migrate labels")
+			resp.add(bulkItemResult{Name: secret.Name, Status: status, Error: msg})
+			continue
+		}
+		resp.add(bulkItemResult{Name: secret.Name, Status: http.StatusOK})
+	}
+	if resp.Succeeded > 0 && !dryRun {
+		s.writes.noteWrite(userNamespace)
+	}
+
+	logSafef("label migration: namespace=%q from=%q from_key=%q dry_run=%t matched=%d migrated=%d failed=%d", userNamespace, strings.Join(from, ","), legacyManagedByLabelKey, dryRun, resp.Matched, resp.Succeeded, resp.Failed)
+	writeJSON(w, resp.status(), resp)
+}
+
+// managedFlag is only populated for the managed=all debug view, where the
+// list mixes managed and unmanaged secrets.
+func managedFlag(includeAll bool, secret *corev1.Secret) *bool {
+	if !includeAll {
+		return nil
+	}
+	managed := isManagedSecret(secret)
+	return &managed
+}
+
+func stringSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// createdByAnnotation records the identity that created a secret through this
+// API. The server owns it: clients can neither set it on create nor change it
+// on update.
+const createdByAnnotation = "kubeflow-secrets/created-by"
+
+// versionAnnotation records the build version that last wrote a secret when
+// STAMP_VERSION is enabled. Off by default: it changes on every upgrade,
+// which is noise for teams diffing secrets in GitOps.
+const versionAnnotation = "kubeflow-secrets/version"
+
+// Provenance annotations, stamped when PROVENANCE_ANNOTATIONS is enabled.
+// created-at is written once; modified-by and modified-at are refreshed by
+// every write through this API. Like created-by, they are server-owned.
+const (
+	createdAtAnnotation  = "kubeflow-secrets/created-at"
+	modifiedByAnnotation = "kubeflow-secrets/modified-by"
+	modifiedAtAnnotation = "kubeflow-secrets/modified-at"
+)
+
+// serverOwnedAnnotations are kept from the stored secret on update and
+// stripped from imported manifests.
+var serverOwnedAnnotations = []string{createdByAnnotation, createdAtAnnotation, modifiedByAnnotation, modifiedAtAnnotation}
+
+func (s *server) stampVersion(secret *corev1.Secret) {
+	if !s.stampVersionEnabled {
+		return
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string, 1)
+	}
+	secret.Annotations[versionAnnotation] = version
+}
+
+func (s *server) stampCreator(r *http.Request, secret *corev1.Secret) {
+	user, _, err := s.identityFromRequest(r)
+	if err != nil {
+		return
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string, 1)
+	}
+	secret.Annotations[createdByAnnotation] = user
+}
+
+// stampProvenance records who wrote a secret and when. On create it also
+// sets created-at and drops any provenance the payload tried to supply, so
+// the annotations are only ever written by the server.
+func (s *server) stampProvenance(r *http.Request, secret *corev1.Secret, created bool) {
+	if created {
+		delete(secret.Annotations, createdAtAnnotation)
+		delete(secret.Annotations, modifiedByAnnotation)
+		delete(secret.Annotations, modifiedAtAnnotation)
+	}
+	if !s.provenanceEnabled {
+		return
+	}
+	user, _, err := s.identityFromRequest(r)
+	if err != nil {
+		return
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string, 3)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if created {
+		secret.Annotations[createdAtAnnotation] = now
+	}
+	secret.Annotations[modifiedByAnnotation] = user
+	secret.Annotations[modifiedAtAnnotation] = now
+}
+
+// preserveServerAnnotations copies annotations owned by the server from the
+// stored secret so an update payload cannot drop or forge them.
+func preserveServerAnnotations(existing, updated *corev1.Secret) {
+	for _, key := range serverOwnedAnnotations {
+		value, ok := existing.Annotations[key]
+		if !ok {
+			delete(updated.Annotations, key)
+			continue
+		}
+		if updated.Annotations == nil {
+			updated.Annotations = make(map[string]string, len(serverOwnedAnnotations))
+		}
+		updated.Annotations[key] = value
+	}
+}
+
+func createdByUser(secret *corev1.Secret, user string) bool {
+	creator, ok := secret.Annotations[createdByAnnotation]
+	return ok && normalizeIdentity(creator) == normalizeIdentity(user)
+}
+
+// isHiddenAnnotation reports whether key starts with one of
+// HIDDEN_ANNOTATION_PREFIXES. Those annotations are usually written by
+// controllers or kubectl, clutter the UI and, for last-applied-configuration,
+// can hold an earlier copy of the data.
+func (s *server) isHiddenAnnotation(key string) bool {
+	for _, prefix := range s.hiddenAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleAnnotations returns annotations without the hidden ones, unless the
+// caller asked for ?showAllAnnotations=true.
+func (s *server) visibleAnnotations(r *http.Request, annotations map[string]string) map[string]string {
+	if len(s.hiddenAnnotationPrefixes) == 0 || r.URL.Query().Get("showAllAnnotations") == "true" {
+		return annotations
+	}
+	visible := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if !s.isHiddenAnnotation(key) {
+			visible[key] = value
+		}
+	}
+	return visible
+}
+
+// keepHiddenAnnotations adds the stored hidden annotations an update payload
+// does not mention. Clients only ever saw the filtered set, so sending it
+// back must not drop the rest.
+func (s *server) keepHiddenAnnotations(existing *corev1.Secret, annotations map[string]string) {
+	for key, value := range existing.Annotations {
+		if _, ok := annotations[key]; !ok && s.isHiddenAnnotation(key) {
+			annotations[key] = value
+		}
+	}
+}
+
+// secretDetail is secretToDetail with the caller's annotation view applied.
+func (s *server) secretDetail(r *http.Request, secret *corev1.Secret) secretDetailResponse {
+	detail := secretToDetail(secret)
+	detail.Annotations = s.visibleAnnotations(r, detail.Annotations)
+	return detail
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// handleSecretBatchCreate serves POST /api/secrets:batchCreate: one template
+// plus per-item overrides, for sets of similar secrets such as per-environment
+// copies. Items are created one by one with the same validation and quota
+// precheck as POST /api/secrets; there is no rollback, the bulk response
+// says which ones exist.
+func (s *server) handleSecretBatchCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userNamespace, impClient, ok := s.userContext(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := s.readBatchCreateRequest(r)
+	if err != nil {
+		if errors.Is(err, errImportTooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("batch body exceeds %d bytes", s.importMaxBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if requestedNamespace := strings.TrimSpace(req.Template.Namespace); requestedNamespace != "" && requestedNamespace != userNamespace {
+		writeError(w, http.StatusForbidden, "cross-namespace access is not allowed")
+		return
+	}
+	if strings.TrimSpace(req.Template.Name) != "" {
+		writeError(w, http.StatusBadRequest, "template.name is not used; name each item instead")
+		return
+	}
+	if len(req.Items) == 0 {
+		writeError(w, http.StatusBadRequest, "items is required")
+		return
+	}
+	if int64(len(req.Items)) > s.importMaxDocuments {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("batch has %d items; at most %d are allowed", len(req.Items), s.importMaxDocuments))
+		return
+	}
+
+	policy, err := s.policyForNamespace(r.Context(), userNamespace)
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+
+	s.runBulk(w, r, "batchCreate", len(req.Items), func(r *http.Request, progress func()) (int, any) {
+		resp := secretBatchCreateResponse{Namespace: userNamespace, bulkResult: newBulkResult(len(req.Items))}
+		for i, item := range req.Items {
+			result := s.batchCreateItem(r, impClient, userNamespace, policy, mergeBatchItem(req.Template, item, userNamespace))
+			result.Document = i + 1
+			resp.add(result)
+			progress()
+		}
+
+		logSafef("secrets batch created: namespace=%q created=%d failed=%d", userNamespace, resp.Succeeded, resp.Failed)
+		return resp.status(), resp
+	})
+}
+
+func (s *server) readBatchCreateRequest(r *http.Request) (secretBatchCreateRequest, error) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			logSafef("failed to close request body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.importMaxBytes+1))
+	if err != nil {
+		return secretBatchCreateRequest{}, errReadRequestBody
+	}
+	if int64(len(body)) > s.importMaxBytes {
+		return secretBatchCreateRequest{}, errImportTooLarge
+	}
+
+	var req secretBatchCreateRequest
+	if err := decodeJSON(body, &req); err != nil {
+		return secretBatchCreateRequest{}, err
+	}
+	if key := invalidUTF8BatchStringDataKey(body); key != "" {
+		return secretBatchCreateRequest{}, invalidUTF8StringDataError(key)
+	}
+	return req, nil
+}
+
+// invalidUTF8BatchStringDataKey is invalidUTF8StringDataKey for a batch
+// body, where stringData sits in the template and in each item.
+func invalidUTF8BatchStringDataKey(body []byte) string {
+	if utf8.Valid(body) {
+		return ""
+	}
+
+	var raw struct {
+		Template json.RawMessage   `json:"template"`
+		Items    []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return ""
+	}
+	for _, part := range append([]json.RawMessage{raw.Template}, raw.Items...) {
+		if key := invalidUTF8StringDataKey(part); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// mergeBatchItem overlays one item on the template. Maps merge key by key
+// with the item winning; managed labels are always applied.
+func mergeBatchItem(template secretUpsertRequest, item secretBatchCreateItem, namespace string) secretUpsertRequest {
+	req := template
+	req.Namespace = namespace
+	req.Name = strings.TrimSpace(item.Name)
+	req.Data = mergeStringMaps(template.Data, item.Data)
+	req.StringData = mergeStringMaps(template.StringData, item.StringData)
+	req.Labels = ensureManagedLabels(mergeStringMaps(template.Labels, item.Labels))
+	req.Annotations = mergeStringMaps(template.Annotations, item.Annotations)
+	return req
+}
+
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(base)+len(overrides))
+	for key, value := range base {
+		out[key] = value
+	}
+	for key, value := range overrides {
+		out[key] = value
+	}
+	return out
+}
+
+func (s *server) batchCreateItem(r *http.Request, client kubernetes.Interface, namespace string, policy namespacePolicy, req secretUpsertRequest) bulkItemResult {
+	result := bulkItemResult{Name: req.Name}
+	if status, msg, ok := s.checkNameCase(r.Context(), client, namespace, req.Name); !ok {
+		result.Status, result.Error = status, msg
+		return result
+	}
+
+	secret, _, err := s.validateAndBuildSecret(req, policy)
+	if err != nil {
+		result.Status, result.Error = http.StatusBadRequest, err.Error()
+		return result
+	}
+	s.stampCreator(r, secret)
+	s.stampProvenance(r, secret, true)
+	s.stampVersion(secret)
+
+	if msg, ok := s.checkSecretQuota(r.Context(), client, namespace); !ok {
+		result.Status, result.Code, result.Error = http.StatusForbidden, errorCodeQuotaExceeded, msg
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.importDocumentTimeout)
+	defer cancel()
+	unlock := s.lockSecret(secret.Namespace, secret.Name)
+	defer unlock()
+
+	created, err := client.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if err != nil {
+		result.Status, result.Error = mapKubeError(err, "failed to create secret")
+		if isQuotaRejection(err) {
+			result.Status, result.Code, result.Error = http.StatusForbidden, errorCodeQuotaExceeded, sanitizeSingleLine(err.Error())
+		}
+		return result
+	}
+	s.writes.noteWrite(namespace)
+	logSafef("secret created: namespace=%q name=%q type=%q source=batch%s", created.Namespace, created.Name, created.Type, s.keyNamesLogField(created))
+	result.Status = http.StatusCreated
+	return result
+}
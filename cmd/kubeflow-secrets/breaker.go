@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+
+	breakerStateClosed   = "closed"
+	breakerStateOpen     = "open"
+	breakerStateHalfOpen = "half-open"
+)
+
+var errCircuitOpen = errors.New("kubernetes API circuit breaker is open")
+
+// circuitBreaker stops calling the admin client after threshold consecutive
+// failures and fails fast for cooldown, so a struggling apiserver is not
+// piled on by every incoming request. After the cooldown the breaker is
+// half-open: one attempt is let through while every other caller keeps
+// failing fast, and that attempt's result reopens or closes the breaker.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int64
+	cooldown  time.Duration
+	failures  int64
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int64, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call may go ahead. probe is true when the call is
+// the single half-open attempt; the caller hands it back to record so only
+// that attempt's outcome frees the probe slot.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	if b.threshold == 0 {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Now().Before(b.openUntil) {
+		return false, errCircuitOpen
+	}
+	if b.failures >= b.threshold {
+		if b.probing {
+			return false, errCircuitOpen
+		}
+		b.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+func (b *circuitBreaker) record(err error, probe bool) {
+	if b.threshold == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	// A canceled call says nothing either way; a half-open breaker lets the
+	// next caller probe instead of closing on it.
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if !countsAsBreakerFailure(err) {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		logSafef("circuit breaker opened: failures=%d cooldown=%s err=%v", b.failures, b.cooldown.String(), err)
+	}
+}
+
+func (b *circuitBreaker) state() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.threshold == 0 || b.failures < b.threshold:
+		return breakerStateClosed
+	case time.Now().Before(b.openUntil):
+		return breakerStateOpen
+	default:
+		return breakerStateHalfOpen
+	}
+}
+
+// countsAsBreakerFailure treats only availability problems as failures.
+// Authorization and client-side errors say nothing about apiserver health.
+func countsAsBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch {
+	case apierrors.IsForbidden(err),
+		apierrors.IsUnauthorized(err),
+		apierrors.IsNotFound(err),
+		apierrors.IsBadRequest(err),
+		apierrors.IsInvalid(err):
+		return false
+	}
+	return true
+}
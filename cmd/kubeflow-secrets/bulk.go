@@ -0,0 +1,33 @@
+package main
+
+import "net/http"
+
+// newBulkResult starts the shared body of endpoints that act on many secrets
+// at once, so clients can process import, label migration and batch results
+// the same way.
+func newBulkResult(capacity int) bulkResult {
+	return bulkResult{Items: make([]bulkItemResult, 0, capacity)}
+}
+
+// add records one item. Failed items without an explicit code get one
+// derived from their status, like writeErrorCode does for whole responses.
+func (b *bulkResult) add(item bulkItemResult) {
+	if item.Status >= http.StatusBadRequest {
+		if item.Code == "" {
+			item.Code = errorCode(item.Status)
+		}
+		b.Failed++
+	} else {
+		b.Succeeded++
+	}
+	b.Items = append(b.Items, item)
+}
+
+// status is 200 when every item succeeded and 207 Multi-Status as soon as
+// one failed, so clients only need to inspect items on a 207.
+func (b *bulkResult) status() int {
+	if b.Failed > 0 {
+		return http.StatusMultiStatus
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIP returns the address to log for r. X-Forwarded-For and X-Real-IP
+// are only believed when the direct peer is in TRUSTED_PROXY_CIDRS; anyone
+// else could put any address there. X-Forwarded-For is walked from the
+// right, skipping trusted proxies, so a client cannot spoof its address by
+// prepending entries.
+func (s *server) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	peer, err := netip.ParseAddr(remote)
+	if err != nil || !s.isTrustedProxy(peer) {
+		return remote
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			client = addr
+			if !s.isTrustedProxy(addr) {
+				break
+			}
+		}
+		return client.Unmap().String()
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+	return remote
+}
+
+func (s *server) isTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
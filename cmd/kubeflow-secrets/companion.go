@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// companionOfLabelKey marks auxiliary ConfigMaps (revision history, soft
+// delete payloads and the like) with the name of the secret they belong to.
+// They carry the managed-by label too, so only objects this app created are
+// ever collected.
+const companionOfLabelKey = "kubeflow-secrets/companion-of"
+
+func companionSelector(secretName string) string {
+	if secretName == "" {
+		return managedLabelSelector() + "," + companionOfLabelKey
+	}
+	return fmt.Sprintf("%s,%s=%s", managedLabelSelector(), companionOfLabelKey, secretName)
+}
+
+// deleteCompanions removes the companion objects of one secret and returns
+// how many were deleted.
+func deleteCompanions(ctx context.Context, client kubernetes.Interface, namespace, secretName string) (int, error) {
+	list, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: companionSelector(secretName)})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, cm := range list.Items {
+		if err := client.CoreV1().ConfigMaps(namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{}); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// collectOrphanedCompanions removes companion objects whose secret no longer
+// exists, e.g. because the server crashed between the two deletes.
+func collectOrphanedCompanions(ctx context.Context, client kubernetes.Interface, namespace string) (int, error) {
+	companions, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: companionSelector("")})
+	if err != nil {
+		return 0, err
+	}
+	if len(companions.Items) == 0 {
+		return 0, nil
+	}
+
+	secrets, err := listSecretsBySelector(ctx, client, namespace, managedLabelSelector())
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]struct{}, len(secrets))
+	for _, secret := range secrets {
+		existing[secret.Name] = struct{}{}
+	}
+
+	deleted := 0
+	for _, cm := range companions.Items {
+		if _, ok := existing[cm.Labels[companionOfLabelKey]]; ok {
+			continue
+		}
+		if err := client.CoreV1().ConfigMaps(namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{}); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *server) handleAdminGC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.isAdminRequest(r) {
+		writeError(w, http.StatusForbidden, "admin group required")
+		return
+	}
+
+	userNamespace, impClient, ok := s.userContext(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := collectOrphanedCompanions(r.Context(), impClient, userNamespace)
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to collect orphaned objects")
+		logSafef("companion gc failed: namespace=%q deleted=%d err=%v", userNamespace, deleted, err)
+		writeError(w, status, msg)
+		return
+	}
+
+	logSafef("companion gc: namespace=%q deleted=%d", userNamespace, deleted)
+	writeJSON(w, http.StatusOK, gcResponse{Namespace: userNamespace, Deleted: deleted})
+}
+
+// runCompanionSweep periodically collects orphans in every Profile namespace
+// using the service account's own client. It only runs when
+// COMPANION_GC_INTERVAL is set, because it needs configmap list/delete rights
+// the base RBAC does not grant.
+func (s *server) runCompanionSweep(ctx context.Context, interval time.Duration) {
+	client, err := kubernetes.NewForConfig(s.baseConfig)
+	if err != nil {
+		logSafef("companion sweep disabled: client init error=%v", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		profiles, err := s.listProfiles(ctx, metav1.ListOptions{})
+		if err != nil {
+			logSafef("companion sweep failed: profile list error=%v", err)
+			continue
+		}
+		total := 0
+		for i := range profiles.Items {
+			namespace := s.newProfileNamespace(&profiles.Items[i]).Namespace
+			if namespace == "" {
+				continue
+			}
+			deleted, err := collectOrphanedCompanions(ctx, client, namespace)
+			total += deleted
+			if err != nil {
+				logSafef("companion sweep failed: namespace=%q err=%v", namespace, err)
+			}
+		}
+		if total > 0 {
+			logSafef("companion sweep: deleted=%d", total)
+		}
+	}
+}
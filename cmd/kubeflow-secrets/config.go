@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	defaultRegistryTestTimeout   = 5 * time.Second
+	defaultEventsLimit           = 100
+	defaultEventsWindow          = 24 * time.Hour
+	defaultMaxLabels             = 64
+	defaultMaxAnnotations        = 64
+	defaultImportMaxBytes        = 8 << 20
+	defaultImportMaxDocuments    = 200
+	defaultImportDocumentTimeout = 10 * time.Second
+)
+
+type serverOptions struct {
+	userHeader               string
+	groupsHeader             string
+	legacyUserHeader         string
+	registryTestEnabled      bool
+	registryTestTimeout      time.Duration
+	serveUI                  bool
+	metricsEnabled           bool
+	uiFallbackPage           bool
+	sortedPaginationMax      int64
+	listDefaultLimit         int64
+	listMaxLimit             int64
+	cursorSigningKey         string
+	cursorTTL                time.Duration
+	adminGroups              []string
+	legacyManagedValues      []string
+	managedByLabelKey        string
+	legacyManagedByLabelKey  string
+	defaultSecretType        corev1.SecretType
+	maxNameLength            int64
+	maxLabels                int64
+	maxAnnotations           int64
+	requiredLabels           []string
+	allowedRegistries        []string
+	rejectNullBytes          bool
+	strictTypeKeys           bool
+	enforceTypeImmutable     bool
+	trimStringValues         bool
+	warningChecks            []string
+	quotaCheckEnabled        bool
+	stampVersion             bool
+	stampProvenance          bool
+	namespaceRecentTracking  bool
+	breakerThreshold         int64
+	breakerCooldown          time.Duration
+	writeLockEnabled         bool
+	readDedupEnabled         bool
+	listSnapshotInterval     time.Duration
+	companionGCInterval      time.Duration
+	logKeyNames              bool
+	logFormat                string
+	hiddenAnnotationPrefixes []string
+	profileOwnerPath         []string
+	profileOwnerLabel        string
+	profileNamespacePath     []string
+	identityNormalize        *regexp.Regexp
+	eventsLimit              int64
+	eventsWindow             time.Duration
+	recentWindowMax          time.Duration
+	namespaceAllowlist       []string
+	namespaceDenylist        []string
+	trustedProxies           []netip.Prefix
+	importMaxBytes           int64
+	importMaxDocuments       int64
+	importDocumentTimeout    time.Duration
+	asyncWorkers             int64
+	asyncQueueSize           int64
+	asyncResultTTL           time.Duration
+	asyncMaxPerUser          int64
+	otlpEndpoint             string
+	serviceName              string
+}
+
+func loadServerOptions() (serverOptions, error) {
+	registryTestEnabled, err := envBool("REGISTRY_TEST_ENABLED", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	registryTestTimeout, err := envDuration("REGISTRY_TEST_TIMEOUT", defaultRegistryTestTimeout)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	serveUI, err := envBool("SERVE_UI", true)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	metricsEnabled, err := envBool("METRICS_ENABLED", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	uiFallbackPage, err := envBool("UI_FALLBACK_PAGE", true)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	sortedPaginationMax, err := envInt("SORTED_PAGINATION_MAX", defaultSortedPaginationMax)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	listDefaultLimit, err := envInt("LIST_DEFAULT_LIMIT", 0)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	listMaxLimit, err := envInt("LIST_MAX_LIMIT", defaultListMaxLimit)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	maxNameLength, err := envInt("MAX_SECRET_NAME_LENGTH", int64(validation.DNS1123SubdomainMaxLength))
+	if err != nil {
+		return serverOptions{}, err
+	}
+	maxLabels, err := envInt("MAX_LABELS", defaultMaxLabels)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	maxAnnotations, err := envInt("MAX_ANNOTATIONS", defaultMaxAnnotations)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	rejectNullBytes, err := envBool("REJECT_NULL_BYTES", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	strictTypeKeys, err := envBool("STRICT_TYPE_KEYS", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	enforceTypeImmutable, err := envBool("ENFORCE_TYPE_IMMUTABLE", true)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	trimStringValues, err := envBool("TRIM_STRING_VALUES", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	warningChecks := []string{warningLargeValue, warningPrivateKey, warningUnusualRegistry, warningWeakPassword}
+	if raw, ok := os.LookupEnv("SECRET_WARNINGS"); ok {
+		warningChecks, err = parseWarningChecks("SECRET_WARNINGS", normalizeGroups([]string{raw}))
+		if err != nil {
+			return serverOptions{}, err
+		}
+	}
+	cursorTTL, err := envDuration("CURSOR_TTL", defaultCursorTTL)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	quotaCheckEnabled, err := envBool("QUOTA_CHECK_ENABLED", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	stampVersion, err := envBool("STAMP_VERSION", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	stampProvenance, err := envBool("PROVENANCE_ANNOTATIONS", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	namespaceRecentTracking, err := envBool("NAMESPACE_RECENT_TRACKING", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	breakerThreshold, err := envInt("CIRCUIT_BREAKER_THRESHOLD", defaultBreakerThreshold)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	breakerCooldown, err := envDuration("CIRCUIT_BREAKER_COOLDOWN", defaultBreakerCooldown)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	writeLockEnabled, err := envBool("WRITE_LOCK_ENABLED", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	readDedupEnabled, err := envBool("READ_DEDUP_ENABLED", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	listSnapshotInterval, err := envDuration("LIST_SNAPSHOT_INTERVAL", 0)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	companionGCInterval, err := envDuration("COMPANION_GC_INTERVAL", 0)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	logKeyNames, err := envBool("LOG_KEY_NAMES", false)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	hiddenAnnotationPrefixes := []string{lastAppliedAnnotation}
+	if raw, ok := os.LookupEnv("HIDDEN_ANNOTATION_PREFIXES"); ok {
+		hiddenAnnotationPrefixes = normalizeGroups([]string{raw})
+	}
+	profileOwnerPath, err := parseFieldPath("PROFILE_OWNER_FIELD_PATH", envOrDefault("PROFILE_OWNER_FIELD_PATH", "spec.owner.name"))
+	if err != nil {
+		return serverOptions{}, err
+	}
+	var profileNamespacePath []string
+	if raw := os.Getenv("PROFILE_NAMESPACE_FIELD_PATH"); raw != "" {
+		profileNamespacePath, err = parseFieldPath("PROFILE_NAMESPACE_FIELD_PATH", raw)
+		if err != nil {
+			return serverOptions{}, err
+		}
+	}
+	identityNormalize, err := parseIdentityRegex("IDENTITY_NORMALIZE_REGEX", os.Getenv("IDENTITY_NORMALIZE_REGEX"))
+	if err != nil {
+		return serverOptions{}, err
+	}
+	eventsLimit, err := envInt("EVENTS_LIMIT", defaultEventsLimit)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	eventsWindow, err := envDuration("EVENTS_WINDOW", defaultEventsWindow)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	recentWindowMax, err := envDuration("RECENT_WINDOW_MAX", defaultRecentWindowMax)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	trustedProxies, err := parseCIDRs("TRUSTED_PROXY_CIDRS", envList("TRUSTED_PROXY_CIDRS"))
+	if err != nil {
+		return serverOptions{}, err
+	}
+	importMaxBytes, err := envInt("IMPORT_MAX_BYTES", defaultImportMaxBytes)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	importMaxDocuments, err := envInt("IMPORT_MAX_DOCUMENTS", defaultImportMaxDocuments)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	importDocumentTimeout, err := envDuration("IMPORT_DOCUMENT_TIMEOUT", defaultImportDocumentTimeout)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	asyncWorkers, err := envInt("ASYNC_WORKERS", defaultAsyncWorkers)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	asyncQueueSize, err := envInt("ASYNC_QUEUE_SIZE", defaultAsyncQueueSize)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	asyncResultTTL, err := envDuration("ASYNC_RESULT_TTL", defaultAsyncResultTTL)
+	if err != nil {
+		return serverOptions{}, err
+	}
+	asyncMaxPerUser, err := envInt("ASYNC_MAX_PER_USER", defaultAsyncPerUser)
+	if err != nil {
+		return serverOptions{}, err
+	}
+
+	return serverOptions{
+		userHeader:               envOrDefault("USER_HEADER", "kubeflow-userid"),
+		groupsHeader:             envOrDefault("GROUPS_HEADER", "kubeflow-groups"),
+		legacyUserHeader:         envOrDefault("LEGACY_USER_HEADER", ""),
+		registryTestEnabled:      registryTestEnabled,
+		registryTestTimeout:      registryTestTimeout,
+		serveUI:                  serveUI,
+		metricsEnabled:           metricsEnabled,
+		uiFallbackPage:           uiFallbackPage,
+		sortedPaginationMax:      sortedPaginationMax,
+		listDefaultLimit:         listDefaultLimit,
+		listMaxLimit:             listMaxLimit,
+		cursorSigningKey:         os.Getenv("CURSOR_SIGNING_KEY"),
+		cursorTTL:                cursorTTL,
+		adminGroups:              envList("ADMIN_GROUPS"),
+		legacyManagedValues:      envList("LEGACY_MANAGED_BY_VALUES"),
+		managedByLabelKey:        envOrDefault("MANAGED_BY_LABEL_KEY", defaultManagedByLabelKey),
+		legacyManagedByLabelKey:  envOrDefault("MANAGED_BY_LEGACY_LABEL_KEY", ""),
+		defaultSecretType:        corev1.SecretType(envOrDefault("DEFAULT_SECRET_TYPE", string(corev1.SecretTypeOpaque))),
+		maxNameLength:            maxNameLength,
+		maxLabels:                maxLabels,
+		maxAnnotations:           maxAnnotations,
+		requiredLabels:           envList("REQUIRED_LABELS"),
+		allowedRegistries:        normalizeRegistries(envList("ALLOWED_REGISTRIES")),
+		rejectNullBytes:          rejectNullBytes,
+		strictTypeKeys:           strictTypeKeys,
+		enforceTypeImmutable:     enforceTypeImmutable,
+		trimStringValues:         trimStringValues,
+		warningChecks:            warningChecks,
+		quotaCheckEnabled:        quotaCheckEnabled,
+		stampVersion:             stampVersion,
+		stampProvenance:          stampProvenance,
+		namespaceRecentTracking:  namespaceRecentTracking,
+		breakerThreshold:         breakerThreshold,
+		breakerCooldown:          breakerCooldown,
+		writeLockEnabled:         writeLockEnabled,
+		readDedupEnabled:         readDedupEnabled,
+		listSnapshotInterval:     listSnapshotInterval,
+		companionGCInterval:      companionGCInterval,
+		logKeyNames:              logKeyNames,
+		logFormat:                strings.ToLower(envOrDefault("LOG_FORMAT", logFormatText)),
+		hiddenAnnotationPrefixes: hiddenAnnotationPrefixes,
+		profileOwnerPath:         profileOwnerPath,
+		profileOwnerLabel:        envOrDefault("PROFILE_OWNER_LABEL", ""),
+		profileNamespacePath:     profileNamespacePath,
+		identityNormalize:        identityNormalize,
+		eventsLimit:              eventsLimit,
+		eventsWindow:             eventsWindow,
+		recentWindowMax:          recentWindowMax,
+		namespaceAllowlist:       envList("NAMESPACE_ALLOWLIST"),
+		namespaceDenylist:        envList("NAMESPACE_DENYLIST"),
+		trustedProxies:           trustedProxies,
+		importMaxBytes:           importMaxBytes,
+		importMaxDocuments:       importMaxDocuments,
+		importDocumentTimeout:    importDocumentTimeout,
+		asyncWorkers:             asyncWorkers,
+		asyncQueueSize:           asyncQueueSize,
+		asyncResultTTL:           asyncResultTTL,
+		asyncMaxPerUser:          asyncMaxPerUser,
+		otlpEndpoint:             envOrDefault("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		serviceName:              envOrDefault("OTEL_SERVICE_NAME", managedByLabelValue),
+	}, nil
+}
+
+// parseFieldPath splits a dot-separated path into the segments
+// unstructured.NestedString expects, rejecting empty segments.
+func parseFieldPath(key, value string) ([]string, error) {
+	fields := strings.Split(value, ".")
+	for _, field := range fields {
+		if strings.TrimSpace(field) == "" {
+			return nil, fmt.Errorf("invalid %s %q: empty path segment", key, value)
+		}
+	}
+	return fields, nil
+}
+
+// identityCaptureGroup names the IDENTITY_NORMALIZE_REGEX group whose match
+// becomes an extra identity candidate.
+const identityCaptureGroup = "identity"
+
+// parseIdentityRegex compiles IDENTITY_NORMALIZE_REGEX, which must define the
+// named group identityCaptureGroup. An empty value disables it.
+func parseIdentityRegex(key, value string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if re.SubexpIndex(identityCaptureGroup) < 0 {
+		return nil, fmt.Errorf("invalid %s %q: missing named group (?P<%s>...)", key, value, identityCaptureGroup)
+	}
+	return re, nil
+}
+
+// parseCIDRs parses a TRUSTED_PROXY_CIDRS style list. Bare addresses are
+// accepted as single-host prefixes.
+func parseCIDRs(key string, values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid %s entry %q: %w", key, value, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// normalizeRegistries lowercases ALLOWED_REGISTRIES entries and maps Docker
+// Hub's aliases the way registryHostname does.
+func normalizeRegistries(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if host, err := registryHostname(value); err == nil && !strings.HasPrefix(value, "*") {
+			value = host
+		}
+		out = append(out, strings.ToLower(value))
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxInFlightReads bounds how many distinct reads are tracked at once. Reads
+// beyond it go straight to the apiserver instead of waiting for a slot.
+const maxInFlightReads = 1024
+
+// readGroup collapses identical concurrent reads into one apiserver call
+// with singleflight, bounded to maxInFlightReads keys. Keys always start
+// with the caller's identity, so callers only ever share results they could
+// have read themselves.
+type readGroup struct {
+	group singleflight.Group
+	mu    sync.Mutex
+	keys  map[string]int
+}
+
+var errSharedReadFailed = errors.New("shared read failed unexpectedly")
+
+func newReadGroup() *readGroup {
+	return &readGroup{keys: make(map[string]int)}
+}
+
+// do runs fn once per key among concurrent callers. The shared call runs
+// without the first caller's cancellation so one closed tab does not fail
+// everyone else's read; the apiserver client timeout still applies.
+func (g *readGroup) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	if _, ok := g.keys[key]; !ok && len(g.keys) >= maxInFlightReads {
+		g.mu.Unlock()
+		return fn(ctx)
+	}
+	g.keys[key]++
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		if g.keys[key]--; g.keys[key] == 0 {
+			delete(g.keys, key)
+		}
+		g.mu.Unlock()
+	}()
+
+	// DoChan re-panics on a fresh goroutine, which would take the process
+	// down, so a panicking fn is turned into an error for every waiter.
+	result := g.group.DoChan(key, func() (val any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logSafef("shared read panicked: err=%v", recovered)
+				val, err = nil, errSharedReadFailed
+			}
+		}()
+		return fn(context.WithoutCancel(ctx))
+	})
+	select {
+	case res := <-result:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readKey scopes a read to the impersonated identity, since that is what the
+// apiserver authorizes against.
+func (s *server) readKey(r *http.Request, parts ...string) string {
+	user, groups, _ := s.identityFromRequest(r)
+	key := []string{user, strings.Join(groups, ","), s.legacyUserFromRequest(r)}
+	return strings.Join(append(key, parts...), "\x00")
+}
+
+type secretPage struct {
+	items         []corev1.Secret
+	continueToken string
+}
+
+// dedupListPage is listManagedSecretPage behind the read group. Callers get
+// their own slice header but must treat the items as read-only.
+func (s *server) dedupListPage(r *http.Request, client kubernetes.Interface, namespace, selector string, page listPage) ([]corev1.Secret, string, error) {
+	if s.reads == nil {
+		return s.listManagedSecretPage(r.Context(), client, namespace, selector, page)
+	}
+
+	key := s.readKey(r, "list", namespace, s.writes.generation(namespace), selector, page.scope, page.continueToken, strconv.FormatInt(page.limit, 10))
+	val, err := s.reads.do(r.Context(), key, func(ctx context.Context) (any, error) {
+		items, next, err := s.listManagedSecretPage(ctx, client, namespace, selector, page)
+		return secretPage{items: items, continueToken: next}, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	result, _ := val.(secretPage)
+	return append([]corev1.Secret(nil), result.items...), result.continueToken, nil
+}
+
+// dedupGetSecret is getManagedSecret behind the read group. Each caller gets
+// a deep copy, so handlers may still mutate what they receive.
+func (s *server) dedupGetSecret(r *http.Request, client kubernetes.Interface, namespace, name string) (*corev1.Secret, error) {
+	if s.reads == nil {
+		return s.getManagedSecret(r.Context(), client, namespace, name)
+	}
+
+	key := s.readKey(r, "get", namespace, s.writes.generation(namespace), name)
+	val, err := s.reads.do(r.Context(), key, func(ctx context.Context) (any, error) {
+		return s.getManagedSecret(ctx, client, namespace, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	secret, _ := val.(*corev1.Secret)
+	return secret.DeepCopy(), nil
+}
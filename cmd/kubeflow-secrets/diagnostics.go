@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// handleDiagnostics serves GET /api/diagnostics, a self-service report of
+// each step a request goes through for the caller: identity headers, Profile
+// resolution, namespace selection, impersonation and finally what RBAC lets
+// the impersonated user do with secrets. Checks stop at the first failure,
+// since every later one depends on it. The report is always a 200; the
+// checks carry the outcome.
+func (s *server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.diagnose(r))
+}
+
+func (s *server) diagnose(r *http.Request) diagnosticsResponse {
+	report := diagnosticsResponse{Groups: []string{}, Checks: make([]diagnosticCheck, 0, 5)}
+	pass := func(name, detail string) {
+		report.Checks = append(report.Checks, diagnosticCheck{Name: name, OK: true, Detail: detail})
+	}
+	fail := func(name, detail string) {
+		report.Checks = append(report.Checks, diagnosticCheck{Name: name, Detail: detail})
+	}
+
+	user, groups, err := s.identityFromRequest(r)
+	if err != nil {
+		fail("identityHeaders", err.Error())
+		return report
+	}
+	report.User, report.Groups = user, groups
+	pass("identityHeaders", fmt.Sprintf("user from %s, %d groups from %s", s.userHeader, len(groups), s.groupsHeader))
+
+	namespaces, err := s.resolveUserNamespaces(r.Context(), user, groups, s.legacyUserFromRequest(r))
+	if err != nil {
+		_, msg := mapNamespaceResolutionError(err)
+		fail("profileResolution", msg)
+		return report
+	}
+	pass("profileResolution", "namespaces: "+strings.Join(namespaces, ", "))
+
+	namespace, ok := resolveNamespaceFromRequest(r, namespaces)
+	if !ok {
+		fail("namespace", fmt.Sprintf("requested namespace %q is not one of yours", requestedNamespace(r)))
+		return report
+	}
+	report.Namespace = namespace
+	pass("namespace", namespace)
+
+	impClient, err := s.newImpersonatedClient(user, groups)
+	if err != nil {
+		fail("impersonation", "failed to create Kubernetes client")
+		return report
+	}
+	pass("impersonation", "client created")
+
+	review, err := impClient.AuthorizationV1().SelfSubjectRulesReviews().Create(r.Context(), &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		_, msg := mapKubeError(err, "rules review failed")
+		fail("secretRules", msg)
+		return report
+	}
+	report.SecretVerbs = secretVerbs(review.Status.ResourceRules)
+	report.RulesIncomplete = review.Status.Incomplete
+	if len(report.SecretVerbs) == 0 {
+		fail("secretRules", "impersonated user has no access to secrets in "+namespace)
+		return report
+	}
+	pass("secretRules", "secrets: "+strings.Join(report.SecretVerbs, ", "))
+	return report
+}
+
+// secretVerbs collects the verbs rules grant on core secrets, sorted.
+func secretVerbs(rules []authorizationv1.ResourceRule) []string {
+	verbs := make([]string, 0)
+	for _, rule := range rules {
+		if !slices.Contains(rule.APIGroups, "") && !slices.Contains(rule.APIGroups, "*") {
+			continue
+		}
+		if !slices.Contains(rule.Resources, "secrets") && !slices.Contains(rule.Resources, "*") {
+			continue
+		}
+		for _, verb := range rule.Verbs {
+			if !slices.Contains(verbs, verb) {
+				verbs = append(verbs, verb)
+			}
+		}
+	}
+	slices.Sort(verbs)
+	return verbs
+}
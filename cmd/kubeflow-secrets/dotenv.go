@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	dotenvUploadNameField = "name"
+	dotenvUploadFileField = "file"
+)
+
+// dotenvBareValue matches values written without quotes. Anything else is
+// double-quoted so whitespace, #, quotes and newlines survive a round trip
+// through common .env parsers.
+var dotenvBareValue = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// handleSecretDotenv serves GET /api/secrets/{name}/dotenv?reveal=true, the
+// secret's values as KEY=value lines for a local .env file. Like the key
+// download it only reveals values when asked to with ?reveal=true.
+func (s *server) handleSecretDotenv(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
+	if r.URL.Query().Get("reveal") != "true" {
+		writeError(w, http.StatusBadRequest, "downloading values requires reveal=true")
+		return
+	}
+
+	secret, err := s.dedupGetSecret(r, impClient, userNamespace, secretName)
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to get secret")
+		writeError(w, status, msg)
+		return
+	}
+	body := formatDotenv(secret)
+
+	logSafef("secret dotenv downloaded: namespace=%q name=%q", userNamespace, secretName)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", secretName+".env"))
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
+
+// formatDotenv renders the secret's data sorted by key. Values that are not
+// valid UTF-8, which the JSON views also leave out of stringData, become a
+// comment instead of a corrupt line.
+func formatDotenv(secret *corev1.Secret) string {
+	var b strings.Builder
+	for _, key := range sortedKeys(secret.Data) {
+		value := secret.Data[key]
+		if !utf8.Valid(value) {
+			fmt.Fprintf(&b, "# %s omitted: value is binary, download it from keys/%s/download\n", key, key)
+			continue
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, quoteDotenvValue(string(value)))
+	}
+	return b.String()
+}
+
+func quoteDotenvValue(value string) string {
+	if dotenvBareValue.MatchString(value) {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(value)
+	return `"` + escaped + `"`
+}
+
+// isMultipartUpload reports whether a create carries a .env upload instead
+// of a JSON body.
+func isMultipartUpload(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// readDotenvUpload turns a multipart/form-data create into an upsert request:
+// the name field names the secret and every KEY=value line of the file
+// field becomes a stringData entry. The whole body is held to
+// maxPayloadSize, like a JSON create.
+func (s *server) readDotenvUpload(w http.ResponseWriter, r *http.Request) (secretUpsertRequest, error) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			logSafef("failed to close request body: %v", err)
+		}
+	}()
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxPayloadSize)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return secretUpsertRequest{}, errors.New("invalid multipart/form-data body")
+	}
+	req := secretUpsertRequest{}
+	var file []byte
+	fileSeen := false
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return secretUpsertRequest{}, uploadReadError(err)
+		}
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return secretUpsertRequest{}, uploadReadError(err)
+		}
+		switch part.FormName() {
+		case dotenvUploadNameField:
+			req.Name = string(value)
+		case dotenvUploadFileField:
+			if fileSeen {
+				return secretUpsertRequest{}, errors.New("only one file can be uploaded per secret")
+			}
+			file, fileSeen = value, true
+		default:
+			return secretUpsertRequest{}, fmt.Errorf("unknown form field %q; expected %q and %q", part.FormName(), dotenvUploadNameField, dotenvUploadFileField)
+		}
+	}
+	if !fileSeen {
+		return secretUpsertRequest{}, fmt.Errorf("form field %q with the .env file is required", dotenvUploadFileField)
+	}
+
+	req.StringData, err = parseDotenv(file)
+	if err != nil {
+		return secretUpsertRequest{}, err
+	}
+	return req, nil
+}
+
+var errUploadTooLarge = errors.New("upload too large")
+
+func uploadReadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errUploadTooLarge
+	}
+	return errors.New("invalid multipart/form-data body")
+}
+
+// parseDotenv reads KEY=value lines, the format formatDotenv writes. Blank
+// lines and # comments are skipped and an export prefix is allowed. Values
+// may be bare, single-quoted (literal) or double-quoted with backslash
+// escapes; a quoted value must close on its own line. Errors name the line.
+func parseDotenv(content []byte) (map[string]string, error) {
+	if !utf8.Valid(content) {
+		return nil, errors.New(".env file is not valid UTF-8")
+	}
+	values := make(map[string]string)
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNumber := i + 1
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, raw, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, fmt.Errorf("line %d: invalid key %q: %s", lineNumber, key, strings.Join(errs, ", "))
+		}
+		if _, duplicate := values[key]; duplicate {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNumber, key)
+		}
+		value, err := parseDotenvValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func parseDotenvValue(raw string) (string, error) {
+	if raw == "" || (raw[0] != '"' && raw[0] != '\'') {
+		// An unquoted value ends at an inline comment.
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+
+	quote := raw[0]
+	var b strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == quote:
+			if rest := strings.TrimSpace(raw[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("unexpected text after closing quote: %q", rest)
+			}
+			return b.String(), nil
+		case c == '\\' && quote == '"' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(raw[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", errors.New("unterminated quoted value")
+}
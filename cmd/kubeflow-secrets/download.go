@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// handleSecretKeyDownload serves GET /api/secrets/{name}/keys/{key}/download,
+// the raw bytes of one value as a file. It exists for binary values such as
+// keystores, which the JSON views can only carry base64 encoded. Like the
+// clean YAML view it only reveals the value when asked to with ?reveal=true.
+func (s *server) handleSecretKeyDownload(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName, key string) {
+	if r.URL.Query().Get("reveal") != "true" {
+		writeError(w, http.StatusBadRequest, "downloading a value requires reveal=true")
+		return
+	}
+
+	secret, err := s.dedupGetSecret(r, impClient, userNamespace, secretName)
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to get secret")
+		writeError(w, status, msg)
+		return
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("secret has no key %q", key))
+		return
+	}
+
+	keyField := ""
+	if s.logKeyNames {
+		keyField = fmt.Sprintf(" key=%q", key)
+	}
+	logSafef("secret value downloaded: namespace=%q name=%q%s", userNamespace, secretName, keyField)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", key))
+	w.Header().Set("Content-Length", strconv.Itoa(len(value)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(value)
+}
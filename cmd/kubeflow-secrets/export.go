@@ -0,0 +1,140 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const maxExportNames = 100
+
+// handleSecretExportZip serves POST /api/secrets:exportZip. Every named
+// secret becomes <name>.yaml in the archive, rendered like the clean YAML
+// view: data values are blank unless ?includeData=true. A passphrase
+// encrypts each entry with WinZip AES-256 so the download is not plaintext at
+// rest. All secrets are read before anything is written, so a missing name
+// fails the whole export.
+func (s *server) handleSecretExportZip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userNamespace, impClient, ok := s.userContext(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := s.readExportRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeData := r.URL.Query().Get("includeData") == "true"
+
+	secrets := make([]*corev1.Secret, 0, len(req.Names))
+	for _, name := range req.Names {
+		secret, err := s.getManagedSecret(r.Context(), impClient, userNamespace, name)
+		if err != nil {
+			status, msg := mapKubeError(err, "failed to read secret for export")
+			writeError(w, status, fmt.Sprintf("%s: %s", name, msg))
+			return
+		}
+		secrets = append(secrets, secret)
+	}
+
+	archive, err := buildExportZip(secrets, includeData, req.Passphrase)
+	if err != nil {
+		logSafef("secret export failed: namespace=%q err=%v", userNamespace, err)
+		writeError(w, http.StatusInternalServerError, "failed to build archive")
+		return
+	}
+
+	logSafef("secrets exported: namespace=%q count=%d include_data=%t encrypted=%t", userNamespace, len(secrets), includeData, req.Passphrase != "")
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "secrets-"+userNamespace+".zip"))
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive)
+}
+
+func (s *server) readExportRequest(r *http.Request) (secretExportRequest, error) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			logSafef("failed to close request body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.maxPayloadSize))
+	if err != nil {
+		return secretExportRequest{}, errReadRequestBody
+	}
+	var req secretExportRequest
+	if err := decodeJSON(body, &req); err != nil {
+		return secretExportRequest{}, err
+	}
+
+	seen := make(map[string]struct{}, len(req.Names))
+	names := make([]string, 0, len(req.Names))
+	for _, name := range req.Names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return secretExportRequest{}, errors.New("names must not contain empty entries")
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return secretExportRequest{}, errors.New("names is required")
+	}
+	if len(names) > maxExportNames {
+		return secretExportRequest{}, fmt.Errorf("at most %d secrets can be exported at once", maxExportNames)
+	}
+	req.Names = names
+	return req, nil
+}
+
+func buildExportZip(secrets []*corev1.Secret, includeData bool, passphrase string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now()
+
+	for _, secret := range secrets {
+		content, err := yaml.Marshal(cleanManifest(secret, includeData))
+		if err != nil {
+			return nil, err
+		}
+		name := secret.Name + ".yaml"
+
+		if passphrase != "" {
+			if err := writeEncryptedZipEntry(zw, name, content, passphrase, now); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+)
+
+// features reports which optional capabilities this server has enabled so
+// the UI can hide controls instead of probing endpoints. Keep the keys stable;
+// add an entry whenever a feature gains a configuration switch.
+func (s *server) features() map[string]bool {
+	return map[string]bool{
+		"registryTest":      s.registryTestEnabled,
+		"sortedPagination":  s.sortedPaginationMax > 0,
+		"hiddenSecrets":     true,
+		"adminMode":         len(s.adminGroups) > 0,
+		"labelMigration":    len(s.adminGroups) > 0 && (len(s.legacyManagedValues) > 0 || legacyManagedByLabelKey != ""),
+		"writeLock":         s.writeLocks != nil,
+		"readDedup":         s.reads != nil,
+		"listSnapshot":      s.snapshots != nil,
+		"rejectNullBytes":   s.rejectNullBytes,
+		"strictTypeKeys":    s.strictTypeKeys,
+		"allowedRegistries": len(s.allowedRegistries) > 0,
+		"trimStringValues":  s.trimStringValues,
+		"warnings":          len(s.warningChecks) > 0,
+		"quotaCheck":        s.quotaCheckEnabled,
+		"tracing":           s.tracer != nil,
+		"hiddenAnnotations": len(s.hiddenAnnotationPrefixes) > 0,
+		"asyncOperations":   s.operations != nil,
+		"recentNamespaces":  s.recentNamespaces != nil,
+	}
+}
+
+func (s *server) handleFeatures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, featuresResponse{Features: s.features()})
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+)
+
+// writeTracker counts successful writes per namespace. Any shared or cached
+// read path keys on the count, so a read issued after a write this pod
+// acknowledged never reuses a result fetched before it (read-your-writes).
+// It only sees this pod's writes, which is all a caller behind session
+// affinity can observe.
+type writeTracker struct {
+	mu          sync.Mutex
+	generations map[string]uint64
+}
+
+func newWriteTracker() *writeTracker {
+	return &writeTracker{generations: make(map[string]uint64)}
+}
+
+func (t *writeTracker) noteWrite(namespace string) {
+	t.mu.Lock()
+	t.generations[namespace]++
+	t.mu.Unlock()
+}
+
+func (t *writeTracker) generation(namespace string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strconv.FormatUint(t.generations[namespace], 10)
+}
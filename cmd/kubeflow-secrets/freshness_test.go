@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreateIsVisibleInNextList(t *testing.T) {
+	srv := newTestServer(t, map[string]string{
+		"READ_DEDUP_ENABLED":     "true",
+		"LIST_SNAPSHOT_INTERVAL": "1m",
+	})
+	client := fake.NewClientset()
+
+	list := func() (secretListResponse, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		srv.handleSecretsList(rec, newTestRequest(srv, http.MethodGet, "/api/secrets", ""), client, testNamespace)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list: status %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp secretListResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		return resp, rec.Header().Get(cacheHeader)
+	}
+
+	if resp, _ := list(); len(resp.Items) != 0 {
+		t.Fatalf("first list: got %d items, want 0", len(resp.Items))
+	}
+	if resp, cache := list(); len(resp.Items) != 0 || cache != "hit" {
+		t.Fatalf("second list: got %d items and X-Cache %q, want 0 from the snapshot", len(resp.Items), cache)
+	}
+
+	rec := httptest.NewRecorder()
+	body := `{"name":"db","type":"Opaque","stringData":{"password":"s3cr3t-value"}}`
+	srv.handleSecretCreate(rec, newTestRequest(srv, http.MethodPost, "/api/secrets", body), client, testNamespace)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp, _ := list()
+	if len(resp.Items) != 1 || resp.Items[0].Name != "db" {
+		t.Fatalf("list after create: got %+v, want the new secret", resp.Items)
+	}
+}
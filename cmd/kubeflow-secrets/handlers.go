@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 )
@@ -20,7 +29,7 @@ func (s *server) withLogging(next http.Handler) http.Handler {
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(rec, r)
 
-		if r.URL.Path == "/healthz" {
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
 			return
 		}
 
@@ -31,31 +40,89 @@ func (s *server) withLogging(next http.Handler) http.Handler {
 			r.Header.Get("traceparent"),
 		)
 
-		logSafef(
-			"request method=%s path=%s status=%d duration=%s remote=%s user=%q request_id=%q",
-			r.Method,
-			r.URL.Path,
-			rec.status,
-			time.Since(start).String(),
-			r.RemoteAddr,
-			user,
-			reqID,
-		)
+		s.logRequest(requestLog{
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Status:    rec.status,
+			Remote:    r.RemoteAddr,
+			ClientIP:  s.clientIP(r),
+			User:      user,
+			RequestID: reqID,
+			duration:  time.Since(start),
+		})
 	})
 }
 
 func (s *server) withJSON(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.Header.Get("Accept"), jsonAPIMediaType) {
+			w = &jsonAPIErrorWriter{ResponseWriter: w}
+		}
+		if r.URL.Query().Get(prettyQueryParam) == "true" {
+			w = &prettyJSONWriter{ResponseWriter: w}
+		}
 		next(w, r)
 	}
 }
 
+// handleNotFound answers every unrouted path when SERVE_UI=false, so
+// API-only deployments return JSON errors instead of the UI.
+func handleNotFound(w http.ResponseWriter, _ *http.Request) {
+	writeError(w, http.StatusNotFound, "not found")
+}
+
 func (s *server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("ok"))
 }
 
+const (
+	readyzTimeout     = 2 * time.Second
+	readyzLogInterval = time.Minute
+)
+
+// handleReadyz reports whether the server should receive traffic: the
+// control plane must answer a one-item Profile list, which every user
+// request needs for namespace resolution. /healthz stays a pure liveness
+// check so an apiserver outage never restarts the pod. The list goes
+// through the admin circuit breaker, so while it is open the probe fails
+// fast instead of adding load.
+func (s *server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyzTimeout)
+	defer cancel()
+
+	resp := readyzResponse{ControlPlane: "reachable"}
+	status := http.StatusOK
+	if _, err := s.listProfiles(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		resp.ControlPlane = "unreachable"
+		status = http.StatusServiceUnavailable
+		if s.readyzLog.allow(time.Now()) {
+			logSafef("readiness check failed (logged at most once per %s): err=%v", readyzLogInterval, err)
+		}
+	}
+	resp.CircuitBreaker = s.adminBreaker.state()
+	writeJSON(w, status, resp)
+}
+
+// logThrottle lets a recurring log line through at most once per interval,
+// for checks such as probes that fail the same way every few seconds.
+type logThrottle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+func (t *logThrottle) allow(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.last.IsZero() && now.Sub(t.last) < t.interval {
+		return false
+	}
+	t.last = now
+	return true
+}
+
 func (s *server) handleNamespaces(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -69,7 +136,7 @@ func (s *server) handleNamespaces(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	namespaces, err := s.resolveUserNamespaces(r.Context(), user, groups)
+	profiles, err := s.resolveUserProfiles(r.Context(), user, groups, s.legacyUserFromRequest(r))
 	if err != nil {
 		logSafef("namespace resolution failed: user=%q err=%v", sanitizeForLog(user), err)
 		status, msg := mapNamespaceResolutionError(err)
@@ -77,8 +144,49 @@ func (s *server) handleNamespaces(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	primary := primaryNamespace(profiles)
+	if s.recentNamespaces != nil {
+		rankProfileNamespaces(profiles, s.recentNamespaces.recent(normalizeIdentity(user)))
+	}
+	query := r.URL.Query()
+	profiles, total, err := filterProfileNamespaces(profiles, strings.TrimSpace(query.Get("q")), strings.TrimSpace(query.Get("limit")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	namespaces := profileNamespaceNames(profiles)
 	logSafef("namespace resolved: user=%q namespaces=%q", sanitizeForLog(user), strings.Join(namespaces, ","))
-	writeJSON(w, http.StatusOK, namespaceResponse{Namespaces: namespaces})
+	resp := namespaceResponse{Namespaces: namespaces, Profiles: profiles, Primary: primary, Total: total}
+	if r.URL.Query().Get("counts") == "true" {
+		impClient, err := s.newImpersonatedClient(user, groups)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to create Kubernetes client")
+			return
+		}
+		resp.Counts = countSecretsByType(r.Context(), impClient, namespaces)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// countSecretsByType lists managed secrets per namespace and tallies them by
+// type. Namespaces that fail to list are left out rather than failing the
+// whole response.
+func countSecretsByType(ctx context.Context, client kubernetes.Interface, namespaces []string) map[string]map[corev1.SecretType]int {
+	counts := make(map[string]map[corev1.SecretType]int, len(namespaces))
+	for _, namespace := range namespaces {
+		secrets, err := listSecretsBySelector(ctx, client, namespace, managedLabelSelector())
+		if err != nil {
+			logSafef("namespace counts failed: namespace=%q err=%v", namespace, err)
+			continue
+		}
+		byType := make(map[corev1.SecretType]int)
+		for _, secret := range secrets {
+			byType[secret.Type]++
+		}
+		counts[namespace] = byType
+	}
+	return counts
 }
 
 func (s *server) handleSecrets(w http.ResponseWriter, r *http.Request) {
@@ -98,16 +206,22 @@ func (s *server) handleSecrets(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *server) handleSecretByName(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == secretsPathPrefix {
+		s.handleSecrets(w, r)
+		return
+	}
+
 	userNamespace, impClient, ok := s.userContext(w, r)
 	if !ok {
 		return
 	}
 
-	secretName, subresource, err := parseSecretPath(r.URL.Path)
+	secretName, subresource, key, err := parseSecretPath(r.URL.Path)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "invalid path")
 		return
 	}
+	spanFromContext(r.Context()).setAttr("kubeflow_secrets.operation", strings.TrimSpace(r.Method+" "+subresource))
 
 	switch subresource {
 	case "":
@@ -116,6 +230,8 @@ func (s *server) handleSecretByName(w http.ResponseWriter, r *http.Request) {
 			s.handleSecretGet(w, r, impClient, userNamespace, secretName)
 		case http.MethodPut:
 			s.handleSecretUpdate(w, r, impClient, userNamespace, secretName)
+		case http.MethodPatch:
+			s.handleSecretPatch(w, r, impClient, userNamespace, secretName)
 		case http.MethodDelete:
 			s.handleSecretDelete(w, r, impClient, userNamespace, secretName)
 		default:
@@ -126,13 +242,59 @@ func (s *server) handleSecretByName(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 			return
 		}
+		if err := validateSubresourceRequest(r, subresource); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		s.handleSecretEvents(w, r, impClient, userNamespace, secretName)
 	case secretSubresourceYAML:
 		if r.Method != http.MethodGet {
 			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 			return
 		}
+		if err := validateSubresourceRequest(r, subresource); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		s.handleSecretYAML(w, r, impClient, userNamespace, secretName)
+	case secretSubresourceDotenv:
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if err := validateSubresourceRequest(r, subresource); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.handleSecretDotenv(w, r, impClient, userNamespace, secretName)
+	case secretSubresourceDownload:
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if err := validateSubresourceRequest(r, subresource); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.handleSecretKeyDownload(w, r, impClient, userNamespace, secretName, key)
+	case secretActionTestRegistry:
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		s.handleSecretTestRegistry(w, r, impClient, userNamespace, secretName)
+	case secretActionHide, secretActionUnhide:
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		s.handleSecretSetHidden(w, r, impClient, userNamespace, secretName, subresource == secretActionHide)
+	case secretActionAvailable:
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		s.handleSecretNameAvailable(w, r, impClient, userNamespace, secretName)
 	default:
 		writeError(w, http.StatusBadRequest, "invalid path")
 	}
@@ -153,7 +315,7 @@ func (s *server) userContext(w http.ResponseWriter, r *http.Request) (string, ku
 		return "", nil, false
 	}
 
-	userNamespaces, err := s.resolveUserNamespaces(r.Context(), user, groups)
+	userNamespaces, err := s.resolveUserNamespaces(r.Context(), user, groups, s.legacyUserFromRequest(r))
 	if err != nil {
 		logSafef("request failed: user=%q namespace resolution error=%v", sanitizeForLog(user), err)
 		status, msg := mapNamespaceResolutionError(err)
@@ -169,6 +331,13 @@ func (s *server) userContext(w http.ResponseWriter, r *http.Request) (string, ku
 		return "", nil, false
 	}
 
+	// Multi-profile callers that omit the namespace get the primary one, or
+	// else the first; tell them which namespace the operation targeted.
+	w.Header().Set(resolvedNamespaceHeader, userNamespace)
+	spanFromContext(r.Context()).setAttr("k8s.namespace", userNamespace)
+	if s.recentNamespaces != nil {
+		s.recentNamespaces.touch(normalizeIdentity(user), userNamespace)
+	}
 	return userNamespace, impClient, true
 }
 
@@ -208,33 +377,148 @@ func (s *server) handleSecretsList(w http.ResponseWriter, r *http.Request, impCl
 		return
 	}
 
-	secretList, err := impClient.CoreV1().Secrets(ns).List(r.Context(), metav1.ListOptions{LabelSelector: managedLabelSelector()})
+	page, err := s.parseListPage(r)
 	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sortOrder := r.URL.Query().Get("sort")
+	switch sortOrder {
+	case "", listSortName, listSortModified, listSortModifiedDesc:
+	default:
+		writeError(w, http.StatusBadRequest, "sort must be one of name, modified, -modified")
+		return
+	}
+	withModified := sortOrder == listSortModified || sortOrder == listSortModifiedDesc
+	withValidity := r.URL.Query().Get("validate") == "true"
+
+	typeFilter := corev1.SecretType(strings.TrimSpace(r.URL.Query().Get("type")))
+	if typeFilter != "" {
+		if _, ok := s.allowedTypes[typeFilter]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("secret type %q is not in allowed list", typeFilter))
+			return
+		}
+	}
+
+	mineOf := ""
+	if r.URL.Query().Get("mine") == "true" {
+		user, _, err := s.identityFromRequest(r)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		mineOf = user
+	}
+
+	includeAll := false
+	switch r.URL.Query().Get("managed") {
+	case "":
+	case "all":
+		if !s.isAdminRequest(r) {
+			logSafef("secrets list denied: managed=all requires admin group namespace=%q", ns)
+			writeError(w, http.StatusForbidden, "managed=all requires an admin group")
+			return
+		}
+		includeAll = true
+	default:
+		writeError(w, http.StatusBadRequest, "managed must be omitted or \"all\"")
+		return
+	}
+
+	includeHidden := includeAll || r.URL.Query().Get("includeHidden") == "true"
+	selector := managedLabelSelector()
+	switch {
+	case includeAll:
+		selector = ""
+	case !includeHidden:
+		selector += "," + hiddenKey + "!=true"
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("labelSelector")); raw != "" {
+		// ANDed onto the managed clause, never in place of it, so a caller
+		// selector can only narrow the list.
+		parsed, err := labels.Parse(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid labelSelector: %v", err))
+			return
+		}
+		selector = strings.TrimPrefix(selector+","+parsed.String(), ",")
+	}
+
+	page.scope = strings.Join([]string{selector, sortOrder, mineOf, string(typeFilter)}, "|")
+	page.order = sortOrder
+	if typeFilter != "" {
+		page.fieldSelector = fmt.Sprintf("type=%s", typeFilter)
+	}
+	if mineOf != "" {
+		page.keep = func(sec *corev1.Secret) bool { return createdByUser(sec, mineOf) }
+	}
+	secrets, continueToken, err := s.snapshotListPage(w, r, impClient, ns, selector, page)
+	if err != nil {
+		if errors.Is(err, errInvalidContinue) || errors.Is(err, errExpiredContinue) || errors.Is(err, errContinueMismatch) ||
+			errors.Is(err, errSortNeedsSortedPages) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		status, msg := mapKubeError(err, "failed to list secrets")
 		logSafef("secrets list failed: namespace=%q status=%d err=%v", ns, status, err)
 		writeError(w, status, msg)
 		return
 	}
 
-	items := make([]secretListItem, 0, len(secretList.Items))
-	for _, sec := range secretList.Items {
-		items = append(items, secretListItem{
+	items := make([]secretListItem, 0, len(secrets))
+	for _, sec := range secrets {
+		hidden := isHiddenSecret(&sec)
+		if hidden && !includeHidden {
+			continue
+		}
+		item := secretListItem{
+			UID:               sec.UID,
 			Name:              sec.Name,
 			Namespace:         sec.Namespace,
 			Type:              sec.Type,
 			CreationTimestamp: sec.CreationTimestamp.Time,
-		})
+			Age:               secretAge(sec.CreationTimestamp.Time),
+			Hidden:            hidden,
+			Managed:           managedFlag(includeAll, &sec),
+		}
+		if withModified {
+			modified := lastModifiedTime(&sec)
+			item.LastModified = &modified
+		}
+		if withValidity {
+			missing := missingRequiredKeys(&sec)
+			valid := len(missing) == 0
+			item.Valid = &valid
+			if !valid {
+				item.InvalidReason = fmt.Sprintf("%s secret is missing required keys: %s", sec.Type, strings.Join(missing, ", "))
+			}
+		}
+		items = append(items, item)
 	}
 
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Name < items[j].Name
-	})
+	sortListItems(items, sortOrder)
 
-	writeJSON(w, http.StatusOK, secretListResponse{Items: items})
+	writeJSON(w, http.StatusOK, secretListResponse{Namespace: ns, Items: items, Continue: continueToken})
 }
 
 func (s *server) handleSecretCreate(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace string) {
-	req, err := s.readUpsertRequest(r)
+	returnFull, err := parseReturnMode(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req secretUpsertRequest
+	if isMultipartUpload(r) {
+		req, err = s.readDotenvUpload(w, r)
+	} else {
+		req, err = s.readUpsertRequest(r)
+	}
+	if errors.Is(err, errUploadTooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload exceeds %d bytes", s.maxPayloadSize))
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -249,64 +533,144 @@ func (s *server) handleSecretCreate(w http.ResponseWriter, r *http.Request, impC
 	req.Namespace = userNamespace
 	req.Labels = ensureManagedLabels(req.Labels)
 
-	secret, err := s.validateAndBuildSecret(req)
+	policy, err := s.policyForNamespace(r.Context(), userNamespace)
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+
+	if status, msg, ok := s.checkNameCase(r.Context(), impClient, userNamespace, strings.TrimSpace(req.Name)); !ok {
+		writeError(w, status, msg)
+		return
+	}
+
+	secret, notes, err := s.validateAndBuildSecret(req, policy)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	s.stampCreator(r, secret)
+	s.stampProvenance(r, secret, true)
+	s.stampVersion(secret)
+	warnings := append(notes, s.secretWarnings(secret, policy)...)
+
+	if msg, ok := s.checkSecretQuota(r.Context(), impClient, secret.Namespace); !ok {
+		logSafef("secret create denied: namespace=%q name=%q reason=quota", secret.Namespace, secret.Name)
+		writeErrorCode(w, http.StatusForbidden, errorCodeQuotaExceeded, msg)
+		return
+	}
 
-	created, err := impClient.CoreV1().Secrets(secret.Namespace).Create(r.Context(), secret, metav1.CreateOptions{})
+	unlock := s.lockSecret(secret.Namespace, secret.Name)
+	defer unlock()
+
+	dryRun := isDryRun(r)
+	created, err := impClient.CoreV1().Secrets(secret.Namespace).Create(r.Context(), secret, metav1.CreateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
+		if isQuotaRejection(err) {
+			writeErrorCode(w, http.StatusForbidden, errorCodeQuotaExceeded, sanitizeSingleLine(err.Error()))
+			return
+		}
 		status, msg := mapKubeError(err, "failed to create secret")
 		logSafef("secret create failed: namespace=%q name=%q status=%d err=%v", secret.Namespace, secret.Name, status, err)
 		writeError(w, status, msg)
 		return
 	}
 
-	logSafef("secret created: namespace=%q name=%q type=%q", created.Namespace, created.Name, created.Type)
-	writeJSON(w, http.StatusCreated, secretUpsertResponse{
-		Name:      created.Name,
-		Namespace: created.Namespace,
-		Type:      created.Type,
-	})
+	if dryRun {
+		logSafef("secret create dry run: namespace=%q name=%q type=%q", created.Namespace, created.Name, created.Type)
+		s.writeUpsertResponse(w, r, http.StatusCreated, created, returnFull, warnings)
+		return
+	}
+	s.writes.noteWrite(created.Namespace)
+	logSafef("secret created: namespace=%q name=%q type=%q%s", created.Namespace, created.Name, created.Type, s.keyNamesLogField(created))
+	w.Header().Set("Location", secretLocation(created))
+	s.writeUpsertResponse(w, r, http.StatusCreated, created, returnFull, warnings)
 }
 
 func (s *server) handleSecretGet(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
-	secret, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName)
+	secret, err := s.dedupGetSecret(r, impClient, userNamespace, secretName)
 	if err != nil {
 		status, msg := mapKubeError(err, "failed to get secret")
 		writeError(w, status, msg)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, secretToDetail(secret))
+	detail := s.secretDetail(r, secret)
+	if raw := strings.TrimSpace(r.URL.Query().Get("keys")); raw != "" {
+		if missing := projectDetailKeys(&detail, strings.Split(raw, ",")); len(missing) > 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("secret has no keys: %s", strings.Join(missing, ", ")))
+			return
+		}
+	}
+
+	w.Header().Set(contentHashHeader, contentHash(detail.Data))
+	writeJSON(w, http.StatusOK, detail)
 }
 
+const (
+	eventsPageSize = 500
+	// maxEventsScanned bounds the pages read for one secret's events; a
+	// secret with more than this is flooding events, and the response says
+	// it is truncated.
+	maxEventsScanned = 5000
+)
+
 func (s *server) handleSecretEvents(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
-	if _, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName); err != nil {
+	if _, err := s.dedupGetSecret(r, impClient, userNamespace, secretName); err != nil {
 		status, msg := mapKubeError(err, "failed to get secret events")
 		writeError(w, status, msg)
 		return
 	}
 
+	all := r.URL.Query().Get("all") == "true"
+	window := s.eventsWindow
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "since must be a positive duration such as 30m or 6h")
+			return
+		}
+		window = parsed
+	}
+	eventType := r.URL.Query().Get("type")
+	if eventType != "" && eventType != corev1.EventTypeNormal && eventType != corev1.EventTypeWarning {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("type must be %q or %q", corev1.EventTypeNormal, corev1.EventTypeWarning))
+		return
+	}
+
 	fieldSelector := fmt.Sprintf(
 		"involvedObject.kind=Secret,involvedObject.namespace=%s,involvedObject.name=%s",
 		userNamespace,
 		secretName,
 	)
-	events, err := impClient.CoreV1().Events(userNamespace).List(
-		r.Context(),
-		metav1.ListOptions{FieldSelector: fieldSelector},
-	)
-	if err != nil {
-		status, msg := mapKubeError(err, "failed to list events")
-		writeError(w, status, msg)
-		return
+	// The apiserver returns events in storage order, not newest first, so
+	// every page is read before the window and limit are applied.
+	var events []corev1.Event
+	truncated := false
+	listOptions := metav1.ListOptions{FieldSelector: fieldSelector, Limit: eventsPageSize}
+	for {
+		page, err := impClient.CoreV1().Events(userNamespace).List(r.Context(), listOptions)
+		if err != nil {
+			status, msg := mapKubeError(err, "failed to list events")
+			writeError(w, status, msg)
+			return
+		}
+		events = append(events, page.Items...)
+		if page.Continue == "" {
+			break
+		}
+		if len(events) >= maxEventsScanned {
+			truncated = true
+			break
+		}
+		listOptions.Continue = page.Continue
 	}
 
-	items := make([]secretEventItem, 0, len(events.Items))
-	for _, event := range events.Items {
-		items = append(items, secretEventItem{
+	cutoff := time.Now().Add(-window)
+	items := make([]secretEventItem, 0, len(events))
+	for _, event := range events {
+		item := secretEventItem{
 			Type:      event.Type,
 			Reason:    event.Reason,
 			Message:   event.Message,
@@ -314,28 +678,48 @@ func (s *server) handleSecretEvents(w http.ResponseWriter, r *http.Request, impC
 			FirstSeen: eventTimeOrZero(event.FirstTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
 			LastSeen:  eventTimeOrZero(event.LastTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
 			Source:    sourceSummary(event.Source),
-		})
+		}
+		if !all && item.LastSeen.Before(cutoff) {
+			continue
+		}
+		if eventType != "" && item.Type != eventType {
+			continue
+		}
+		items = append(items, item)
 	}
 
 	sort.SliceStable(items, func(i, j int) bool {
 		return items[i].LastSeen.After(items[j].LastSeen)
 	})
+	if !all && s.eventsLimit > 0 && int64(len(items)) > s.eventsLimit {
+		items = items[:s.eventsLimit]
+		truncated = true
+	}
 
-	writeJSON(w, http.StatusOK, secretEventsResponse{Items: items})
+	writeJSON(w, http.StatusOK, secretEventsResponse{Items: items, Truncated: truncated})
 }
 
 func (s *server) handleSecretYAML(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
-	secret, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName)
+	secret, err := s.dedupGetSecret(r, impClient, userNamespace, secretName)
 	if err != nil {
 		status, msg := mapKubeError(err, "failed to get secret yaml")
 		writeError(w, status, msg)
 		return
 	}
 
-	readonly := secret.DeepCopy()
-	readonly.ManagedFields = nil
+	var manifest any
+	if r.URL.Query().Get("clean") == "true" {
+		clean := cleanManifest(secret, r.URL.Query().Get("reveal") == "true")
+		clean.Metadata.Annotations = s.visibleAnnotations(r, clean.Metadata.Annotations)
+		manifest = clean
+	} else {
+		readonly := secret.DeepCopy()
+		readonly.ManagedFields = nil
+		readonly.Annotations = s.visibleAnnotations(r, readonly.Annotations)
+		manifest = readonly
+	}
 
-	encoded, err := yaml.Marshal(readonly)
+	encoded, err := yaml.Marshal(manifest)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to render yaml")
 		return
@@ -345,6 +729,15 @@ func (s *server) handleSecretYAML(w http.ResponseWriter, r *http.Request, impCli
 }
 
 func (s *server) handleSecretUpdate(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
+	returnFull, err := parseReturnMode(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	unlock := s.lockSecret(userNamespace, secretName)
+	defer unlock()
+
 	existing, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName)
 	if err != nil {
 		status, msg := mapKubeError(err, "failed to update secret")
@@ -374,17 +767,81 @@ func (s *server) handleSecretUpdate(w http.ResponseWriter, r *http.Request, impC
 	}
 	if req.Annotations == nil {
 		req.Annotations = copyStringMap(existing.Annotations)
+	} else {
+		s.keepHiddenAnnotations(existing, req.Annotations)
+	}
+	if r.URL.Query().Get("replace") != "true" {
+		keepExistingData(existing, &req)
+	}
+	s.applySecretUpdate(w, r, impClient, existing, req, returnFull)
+}
+
+// keepExistingData adds the stored keys an update payload does not mention,
+// so sending only the changed values cannot wipe the rest. ?replace=true
+// skips it for callers that send the complete, authoritative key set.
+func keepExistingData(existing *corev1.Secret, req *secretUpsertRequest) {
+	for key, value := range existing.Data {
+		if _, ok := req.Data[key]; ok {
+			continue
+		}
+		if _, ok := req.StringData[key]; ok {
+			continue
+		}
+		if req.DockerCredentials != nil && key == corev1.DockerConfigJsonKey {
+			// Regenerated from the credentials.
+			continue
+		}
+		if req.Data == nil {
+			req.Data = make(map[string]string, len(existing.Data))
+		}
+		req.Data[key] = base64.StdEncoding.EncodeToString(value)
 	}
+}
+
+// applySecretUpdate validates req as the new state of existing and writes
+// it. PUT and PATCH share it once they have built the full request; callers
+// hold the secret's write lock.
+func (s *server) applySecretUpdate(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, existing *corev1.Secret, req secretUpsertRequest, returnFull bool) {
+	userNamespace, secretName := existing.Namespace, existing.Name
 	req.Labels = ensureManagedLabels(req.Labels)
+	if s.enforceTypeImmutable {
+		// The apiserver rejects type changes with a generic field error;
+		// keep an omitted type and explain an explicit change instead.
+		if req.Type == "" {
+			req.Type = existing.Type
+		}
+		if req.Type != existing.Type {
+			writeErrorCode(w, http.StatusUnprocessableEntity, errorCodeImmutableType,
+				fmt.Sprintf("secret type cannot change from %q to %q; delete the secret and create it again with the new type", existing.Type, req.Type))
+			return
+		}
+	}
+
+	policy, err := s.policyForNamespace(r.Context(), userNamespace)
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
 
-	updatedSecret, err := s.validateAndBuildSecret(req)
+	updatedSecret, notes, err := s.validateAndBuildSecret(req, policy)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	updatedSecret.ResourceVersion = existing.ResourceVersion
+	updatedSecret.Immutable = existing.Immutable
+	preserveServerAnnotations(existing, updatedSecret)
+	s.stampProvenance(r, updatedSecret, false)
+	s.stampVersion(updatedSecret)
+	warnings := append(notes, s.secretWarnings(updatedSecret, policy)...)
+	if isImmutableSecret(existing) && secretDataChanged(existing, updatedSecret) {
+		writeErrorCode(w, http.StatusConflict, errorCodeImmutableSecret, immutableSecretNote)
+		return
+	}
 
-	updated, err := impClient.CoreV1().Secrets(userNamespace).Update(r.Context(), updatedSecret, metav1.UpdateOptions{})
+	dryRun := isDryRun(r)
+	updated, err := impClient.CoreV1().Secrets(userNamespace).Update(r.Context(), updatedSecret, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		status, msg := mapKubeError(err, "failed to update secret")
 		logSafef("secret update failed: namespace=%q name=%q status=%d err=%v", userNamespace, secretName, status, err)
@@ -392,29 +849,129 @@ func (s *server) handleSecretUpdate(w http.ResponseWriter, r *http.Request, impC
 		return
 	}
 
-	logSafef("secret updated: namespace=%q name=%q type=%q", updated.Namespace, updated.Name, updated.Type)
-	writeJSON(w, http.StatusOK, secretUpsertResponse{
-		Name:      updated.Name,
-		Namespace: updated.Namespace,
-		Type:      updated.Type,
-	})
+	if dryRun {
+		logSafef("secret update dry run: namespace=%q name=%q type=%q", updated.Namespace, updated.Name, updated.Type)
+		s.writeUpsertResponse(w, r, http.StatusOK, updated, returnFull, warnings)
+		return
+	}
+	s.writes.noteWrite(userNamespace)
+	logSafef("secret updated: namespace=%q name=%q type=%q%s", updated.Namespace, updated.Name, updated.Type, s.keyNamesLogField(updated))
+	s.writeUpsertResponse(w, r, http.StatusOK, updated, returnFull, warnings)
+}
+
+// secretLocation is the API URL of a secret, with its namespace so the link
+// works for callers with several profiles.
+func secretLocation(secret *corev1.Secret) string {
+	return secretsPathPrefix + url.PathEscape(secret.Name) + "?" + url.Values{"namespace": {secret.Namespace}}.Encode()
+}
+
+// isDryRun reports ?dryRun=true on create and update. The write still goes
+// to the apiserver as the caller, so admission and RBAC run, but nothing is
+// persisted.
+func isDryRun(r *http.Request) bool {
+	return r.URL.Query().Get("dryRun") == "true"
+}
+
+func dryRunOption(dryRun bool) []string {
+	if !dryRun {
+		return nil
+	}
+	return []string{metav1.DryRunAll}
+}
+
+// parseReturnMode reads ?return=, which lets create/update answer with the
+// full detail so the UI can skip its follow-up GET.
+func parseReturnMode(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("return") {
+	case "", "minimal":
+		return false, nil
+	case "full":
+		return true, nil
+	default:
+		return false, errors.New("return must be \"minimal\" or \"full\"")
+	}
+}
+
+// parseDeletePrecondition reads the resourceVersion a delete is conditional
+// on, from ?resourceVersion= or If-Match. Neither, or If-Match: *, means an
+// unconditional delete.
+func parseDeletePrecondition(r *http.Request) (string, error) {
+	fromQuery := strings.TrimSpace(r.URL.Query().Get("resourceVersion"))
+	fromHeader := strings.TrimSpace(r.Header.Get("If-Match"))
+	if fromHeader == "*" {
+		fromHeader = ""
+	}
+	fromHeader = strings.Trim(strings.TrimPrefix(fromHeader, "W/"), `"`)
+
+	if fromQuery != "" && fromHeader != "" && fromQuery != fromHeader {
+		return "", errors.New("resourceVersion and If-Match disagree")
+	}
+	return firstNonEmpty(fromQuery, fromHeader), nil
+}
+
+// writeUpsertResponse answers a create/update, with any SECRET_WARNINGS
+// advisories. The full form blanks data values unless ?reveal=true, as the
+// clean YAML view redacts them.
+func (s *server) writeUpsertResponse(w http.ResponseWriter, r *http.Request, status int, secret *corev1.Secret, full bool, warnings []string) {
+	if !full {
+		writeJSON(w, status, secretUpsertResponse{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+			Type:      secret.Type,
+			Warnings:  warnings,
+			DryRun:    isDryRun(r),
+		})
+		return
+	}
+
+	detail := s.secretDetail(r, secret)
+	detail.Warnings = warnings
+	detail.DryRun = isDryRun(r)
+	if r.URL.Query().Get("reveal") != "true" {
+		redactDetail(&detail)
+	}
+	writeJSON(w, status, detail)
 }
 
 func (s *server) handleSecretDelete(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
-	if _, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName); err != nil {
+	resourceVersion, err := parseDeletePrecondition(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	unlock := s.lockSecret(userNamespace, secretName)
+	defer unlock()
+
+	existing, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName)
+	if err != nil {
 		status, msg := mapKubeError(err, "failed to delete secret")
 		writeError(w, status, msg)
 		return
 	}
 
-	if err := impClient.CoreV1().Secrets(userNamespace).Delete(r.Context(), secretName, metav1.DeleteOptions{}); err != nil {
+	opts := metav1.DeleteOptions{}
+	if resourceVersion != "" {
+		opts.Preconditions = &metav1.Preconditions{ResourceVersion: &resourceVersion}
+	}
+	if err := impClient.CoreV1().Secrets(userNamespace).Delete(r.Context(), secretName, opts); err != nil {
+		if apierrors.IsConflict(err) {
+			writeError(w, http.StatusConflict, fmt.Sprintf("secret changed since resourceVersion %s (now %s); reload it before deleting", resourceVersion, existing.ResourceVersion))
+			return
+		}
 		status, msg := mapKubeError(err, "failed to delete secret")
 		logSafef("secret delete failed: namespace=%q name=%q status=%d err=%v", userNamespace, secretName, status, err)
 		writeError(w, status, msg)
 		return
 	}
+	s.writes.noteWrite(userNamespace)
+
+	companions, err := deleteCompanions(r.Context(), impClient, userNamespace, secretName)
+	if err != nil {
+		logSafef("secret companion cleanup failed: namespace=%q name=%q deleted=%d err=%v", userNamespace, secretName, companions, err)
+	}
 
-	logSafef("secret deleted: namespace=%q name=%q", userNamespace, secretName)
+	logSafef("secret deleted: namespace=%q name=%q companions=%d%s", userNamespace, secretName, companions, s.keyNamesLogField(existing))
 	writeJSON(w, http.StatusOK, deleteSecretResponse{
 		Name:      secretName,
 		Namespace: userNamespace,
@@ -438,9 +995,60 @@ func (s *server) readUpsertRequest(r *http.Request) (secretUpsertRequest, error)
 	if err := decodeJSON(body, &req); err != nil {
 		return secretUpsertRequest{}, err
 	}
+	// encoding/json replaces invalid UTF-8 with U+FFFD, so binary pasted into
+	// stringData would be stored corrupted; check the raw bytes instead.
+	if key := invalidUTF8StringDataKey(body); key != "" {
+		return secretUpsertRequest{}, invalidUTF8StringDataError(key)
+	}
 	return req, nil
 }
 
+// keyNamesLogField renders ` keys="a,b"` for mutation log lines when
+// LOG_KEY_NAMES is enabled. Only key names are logged, never values; the
+// result still passes through logSafef's single-line sanitization.
+func (s *server) keyNamesLogField(secret *corev1.Secret) string {
+	if !s.logKeyNames {
+		return ""
+	}
+
+	keys := make([]string, 0, len(secret.Data)+len(secret.StringData))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	for key := range secret.StringData {
+		if _, ok := secret.Data[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf(" keys=%q", strings.Join(keys, ","))
+}
+
+func invalidUTF8StringDataKey(body []byte) string {
+	if utf8.Valid(body) {
+		return ""
+	}
+
+	var raw struct {
+		StringData map[string]json.RawMessage `json:"stringData"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return ""
+	}
+
+	keys := make([]string, 0, len(raw.StringData))
+	for key, value := range raw.StringData {
+		if !utf8.Valid(value) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
 func eventTimeOrZero(values ...time.Time) time.Time {
 	for _, value := range values {
 		if !value.IsZero() {
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func (s *server) handleSecretSetHidden(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string, hidden bool) {
+	unlock := s.lockSecret(userNamespace, secretName)
+	defer unlock()
+
+	existing, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName)
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to update secret")
+		writeError(w, status, msg)
+		return
+	}
+
+	secret := existing.DeepCopy()
+	if hidden {
+		secret.Labels[hiddenKey] = "true"
+	} else {
+		delete(secret.Labels, hiddenKey)
+		delete(secret.Annotations, hiddenKey)
+	}
+	s.stampProvenance(r, secret, false)
+	s.stampVersion(secret)
+
+	updated, err := impClient.CoreV1().Secrets(userNamespace).Update(r.Context(), secret, metav1.UpdateOptions{})
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to update secret")
+		logSafef("secret hidden flag update failed: namespace=%q name=%q status=%d err=%v", userNamespace, secretName, status, err)
+		writeError(w, status, msg)
+		return
+	}
+
+	s.writes.noteWrite(userNamespace)
+	logSafef("secret hidden flag updated: namespace=%q name=%q hidden=%t", updated.Namespace, updated.Name, hidden)
+	writeJSON(w, http.StatusOK, secretHiddenResponse{
+		Name:      updated.Name,
+		Namespace: updated.Namespace,
+		Hidden:    isHiddenSecret(updated),
+	})
+}
+
+// isHiddenSecret reports whether a secret is flagged as hidden from the
+// default list, either via label (set by this API) or annotation (set by hand
+// or by other tooling).
+func isHiddenSecret(secret *corev1.Secret) bool {
+	return secret.Labels[hiddenKey] == "true" || secret.Annotations[hiddenKey] == "true"
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -17,7 +19,13 @@ var (
 )
 
 func writeJSON(w http.ResponseWriter, status int, payload any) {
-	body, err := json.Marshal(payload)
+	var body []byte
+	var err error
+	if wrapsWriter[*prettyJSONWriter](w) {
+		body, err = json.MarshalIndent(payload, "", "  ")
+	} else {
+		body, err = json.Marshal(payload)
+	}
 	if err != nil {
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
@@ -28,8 +36,68 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 	_, _ = w.Write(append(body, '\n'))
 }
 
+// jsonAPIMediaType is the Accept value that switches error bodies to the
+// JSON:API errors envelope. Success bodies keep their regular shape.
+const jsonAPIMediaType = "application/vnd.api+json"
+
+// jsonAPIErrorWriter marks a response whose client negotiated JSON:API
+// errors; writeError looks for it.
+type jsonAPIErrorWriter struct {
+	http.ResponseWriter
+}
+
+func (w *jsonAPIErrorWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// prettyJSONWriter marks a response requested with ?pretty=true; writeJSON
+// indents its body for reading in a terminal.
+type prettyJSONWriter struct {
+	http.ResponseWriter
+}
+
+func (w *prettyJSONWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// wrapsWriter reports whether w is, or wraps, a T. The marker writers can be
+// stacked, so a plain type assertion only sees the outermost one.
+func wrapsWriter[T http.ResponseWriter](w http.ResponseWriter) bool {
+	for w != nil {
+		if _, ok := w.(T); ok {
+			return true
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return false
+		}
+		w = unwrapper.Unwrap()
+	}
+	return false
+}
+
 func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, errorResponse{Error: msg})
+	writeErrorCode(w, status, "", msg)
+}
+
+// writeErrorCode is writeError with a machine-readable code for conditions
+// the UI reacts to specifically, beyond what the status conveys.
+func writeErrorCode(w http.ResponseWriter, status int, code, msg string) {
+	if wrapsWriter[*jsonAPIErrorWriter](w) {
+		if code == "" {
+			code = errorCode(status)
+		}
+		w.Header().Set("Content-Type", jsonAPIMediaType)
+		writeJSON(w, status, jsonAPIErrorResponse{Errors: []jsonAPIError{{
+			Status: strconv.Itoa(status),
+			Code:   code,
+			Detail: msg,
+		}}})
+		return
+	}
+	writeJSON(w, status, errorResponse{Error: msg, Code: code})
+}
+
+// errorCode derives a stable snake_case code from the HTTP status text,
+// e.g. 404 -> "not_found".
+func errorCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
 }
 
 func mapKubeError(err error, fallback string) (int, string) {
@@ -42,12 +110,18 @@ func mapKubeError(err error, fallback string) (int, string) {
 	if apierrors.IsAlreadyExists(err) {
 		return http.StatusConflict, "already exists"
 	}
+	if apierrors.IsConflict(err) {
+		return http.StatusConflict, "secret was modified concurrently; reload it and retry"
+	}
 	if apierrors.IsNotFound(err) {
 		return http.StatusNotFound, "not found"
 	}
 	if apierrors.IsUnauthorized(err) {
 		return http.StatusUnauthorized, "unauthorized"
 	}
+	if apierrors.IsResourceExpired(err) {
+		return http.StatusGone, "continue token expired, restart the listing"
+	}
 	if err == nil {
 		return http.StatusOK, ""
 	}
@@ -58,9 +132,45 @@ func mapNamespaceResolutionError(err error) (int, string) {
 	if errors.Is(err, errProfileNotFound) {
 		return http.StatusForbidden, "no kubeflow profile found for user"
 	}
+	if errors.Is(err, errNamespacesFiltered) {
+		return http.StatusForbidden, "all of your profile namespaces are excluded by server configuration"
+	}
+	if errors.Is(err, errCircuitOpen) {
+		return http.StatusServiceUnavailable, "kubernetes API is temporarily unavailable, retry shortly"
+	}
 	return mapKubeError(err, "failed to resolve user namespace")
 }
 
+// subresourceQueryParams lists the query parameters each read-only
+// subresource accepts on top of namespaceQueryParams. Anything else is
+// rejected so typos surface as a 400 instead of being silently ignored.
+var subresourceQueryParams = map[string][]string{
+	secretSubresourceEvents:   {"all", "since", "type"},
+	secretSubresourceYAML:     {"clean", "reveal", "showAllAnnotations"},
+	secretSubresourceDotenv:   {"reveal"},
+	secretSubresourceDownload: {"reveal"},
+}
+
+// namespaceQueryParams are read by requestedNamespace on every secret route.
+var namespaceQueryParams = []string{"namespace", "ns"}
+
+// prettyQueryParam indents any JSON response; see withJSON.
+const prettyQueryParam = "pretty"
+
+func validateSubresourceRequest(r *http.Request, subresource string) error {
+	if r.ContentLength > 0 || len(r.TransferEncoding) > 0 {
+		return errors.New("request body is not allowed")
+	}
+
+	allowed := subresourceQueryParams[subresource]
+	for param := range r.URL.Query() {
+		if param != prettyQueryParam && !slices.Contains(namespaceQueryParams, param) && !slices.Contains(allowed, param) {
+			return fmt.Errorf("unknown query parameter %q", param)
+		}
+	}
+	return nil
+}
+
 func decodeJSON(body []byte, out any) error {
 	if err := json.Unmarshal(body, out); err != nil {
 		return errInvalidJSONInput
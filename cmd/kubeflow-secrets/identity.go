@@ -4,20 +4,142 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 const maxOwnerNamesInLog = 10
 
-func (s *server) resolveUserNamespaces(ctx context.Context, user string, groups []string) ([]string, error) {
-	profiles, err := s.adminDynamic.Resource(s.profileGVR).List(ctx, metav1.ListOptions{})
+// resolveUserNamespaces returns the profile namespaces the user owns or can
+// work in, minus those excluded by NAMESPACE_ALLOWLIST/NAMESPACE_DENYLIST.
+// legacyUser, when set, is the identity from LEGACY_USER_HEADER and only
+// widens owner matching during IdP migrations; impersonation always uses the
+// primary user.
+func (s *server) resolveUserNamespaces(ctx context.Context, user string, groups []string, legacyUser string) ([]string, error) {
+	profiles, err := s.resolveUserProfiles(ctx, user, groups, legacyUser)
+	if err != nil {
+		return nil, err
+	}
+	return profileNamespaceNames(profiles), nil
+}
+
+// resolveUserProfiles is resolveUserNamespaces keeping each Profile's display
+// name, for views that show namespaces to people.
+func (s *server) resolveUserProfiles(ctx context.Context, user string, groups []string, legacyUser string) ([]profileNamespace, error) {
+	ctx, sp := s.tracer.start(ctx, "profile.resolve", spanKindInternal)
+	profiles, err := s.resolveProfileNamespaces(ctx, user, groups, legacyUser)
+	sp.end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := s.filterNamespaces(profiles)
+	if len(filtered) == 0 {
+		logSafef("namespace resolution filtered: user=%q namespaces=%q", sanitizeForLog(user), strings.Join(profileNamespaceNames(profiles), ","))
+		return nil, errNamespacesFiltered
+	}
+	return filtered, nil
+}
+
+func (s *server) filterNamespaces(profiles []profileNamespace) []profileNamespace {
+	out := make([]profileNamespace, 0, len(profiles))
+	for _, profile := range profiles {
+		if _, denied := s.namespaceDenylist[profile.Namespace]; denied {
+			continue
+		}
+		if len(s.namespaceAllowlist) > 0 {
+			if _, allowed := s.namespaceAllowlist[profile.Namespace]; !allowed {
+				continue
+			}
+		}
+		out = append(out, profile)
+	}
+	return out
+}
+
+// profileDisplayNameAnnotation holds a human-friendly name for a Profile,
+// since namespace names are often generated from the owner's email.
+const profileDisplayNameAnnotation = "kubeflow-secrets/display-name"
+
+// profilePrimaryAnnotation set to "true" marks the Profile whose namespace
+// multi-profile users get when a request names none.
+const profilePrimaryAnnotation = "kubeflow-secrets/primary"
+
+// newProfileNamespace maps a Profile to its namespace: the value at
+// PROFILE_NAMESPACE_FIELD_PATH when configured and set, else the Profile
+// name. A value that is not a DNS label leaves Namespace empty so callers
+// skip the Profile.
+func (s *server) newProfileNamespace(profile *unstructured.Unstructured) profileNamespace {
+	namespace := strings.TrimSpace(profile.GetName())
+	if len(s.profileNamespacePath) > 0 {
+		value, found, err := unstructured.NestedString(profile.Object, s.profileNamespacePath...)
+		if err != nil {
+			logSafef("profile namespace field unreadable: profile=%q err=%v", profile.GetName(), err)
+		} else if found && strings.TrimSpace(value) != "" {
+			namespace = strings.TrimSpace(value)
+		}
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		logSafef("profile namespace invalid: profile=%q namespace=%q err=%s", profile.GetName(), sanitizeForLog(namespace), strings.Join(errs, "; "))
+		namespace = ""
+	}
+
+	return profileNamespace{
+		Namespace:   namespace,
+		DisplayName: strings.TrimSpace(profile.GetAnnotations()[profileDisplayNameAnnotation]),
+		Primary:     profile.GetAnnotations()[profilePrimaryAnnotation] == "true",
+		Profile:     profile.GetName(),
+	}
+}
+
+func profileNamespaceNames(profiles []profileNamespace) []string {
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Namespace)
+	}
+	return names
+}
+
+// sortProfileNamespaces orders primary Profiles first, then by namespace, so
+// the default namespace is always the first entry.
+func sortProfileNamespaces(profiles []profileNamespace) {
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Primary != profiles[j].Primary {
+			return profiles[i].Primary
+		}
+		return profiles[i].Namespace < profiles[j].Namespace
+	})
+}
+
+// primaryNamespace returns the namespace of the first primary Profile, or ""
+// when none is marked.
+func primaryNamespace(profiles []profileNamespace) string {
+	for _, profile := range profiles {
+		if profile.Primary {
+			return profile.Namespace
+		}
+	}
+	return ""
+}
+
+func (s *server) resolveProfileNamespaces(ctx context.Context, user string, groups []string, legacyUser string) ([]profileNamespace, error) {
+	labelled, err := s.ownerLabelledNamespaces(ctx, user, legacyUser)
+	if err != nil {
+		return nil, err
+	}
+	if len(labelled) > 0 {
+		return labelled, nil
+	}
+
+	profiles, err := s.listProfiles(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -27,16 +149,19 @@ func (s *server) resolveUserNamespaces(ctx context.Context, user string, groups
 		return nil, err
 	}
 
-	userCandidates := identityCandidates(user)
-	owned := make([]string, 0, 1)
+	userCandidates := s.identityCandidates(user)
+	legacyCandidates := s.identityCandidates(legacyUser)
+	owned := make([]profileNamespace, 0, 1)
 	ownerNames := make([]string, 0, len(profiles.Items))
-	for _, profile := range profiles.Items {
-		namespace := strings.TrimSpace(profile.GetName())
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		ref := s.newProfileNamespace(profile)
+		namespace := ref.Namespace
 		if namespace == "" {
 			continue
 		}
 
-		ownerName, found, err := unstructured.NestedString(profile.Object, "spec", "owner", "name")
+		ownerName, found, err := unstructured.NestedString(profile.Object, s.profileOwnerPath...)
 		if err != nil {
 			return nil, err
 		}
@@ -45,8 +170,14 @@ func (s *server) resolveUserNamespaces(ctx context.Context, user string, groups
 		}
 
 		ownerNames = append(ownerNames, ownerName)
-		if identitiesMatch(userCandidates, identityCandidates(ownerName)) {
-			owned = append(owned, namespace)
+		ownerCandidates := s.identityCandidates(ownerName)
+		if identitiesMatch(userCandidates, ownerCandidates) {
+			owned = append(owned, ref)
+			continue
+		}
+		if identitiesMatch(legacyCandidates, ownerCandidates) {
+			logSafef("profile matched via legacy identity header: user=%q legacy_user=%q namespace=%q", sanitizeForLog(user), sanitizeForLog(legacyUser), namespace)
+			owned = append(owned, ref)
 			continue
 		}
 
@@ -55,7 +186,7 @@ func (s *server) resolveUserNamespaces(ctx context.Context, user string, groups
 			return nil, err
 		}
 		if allowed {
-			owned = append(owned, namespace)
+			owned = append(owned, ref)
 		}
 	}
 
@@ -64,10 +195,59 @@ func (s *server) resolveUserNamespaces(ctx context.Context, user string, groups
 		return nil, errProfileNotFound
 	}
 
-	sort.Strings(owned)
+	sortProfileNamespaces(owned)
 	return owned, nil
 }
 
+// ownerLabelledNamespaces narrows the Profile list server-side with
+// PROFILE_OWNER_LABEL when configured. Only identity candidates that are valid
+// label values can be matched this way. A hit skips the full scan, which also
+// means namespaces shared with the user via RBAC are not discovered; when no
+// labelled Profile matches, resolveUserNamespaces falls back to the full list.
+func (s *server) ownerLabelledNamespaces(ctx context.Context, user, legacyUser string) ([]profileNamespace, error) {
+	if s.profileOwnerLabel == "" {
+		return nil, nil
+	}
+
+	values := make([]string, 0)
+	for _, candidate := range append(s.identityCandidates(user), s.identityCandidates(legacyUser)...) {
+		if len(validation.IsValidLabelValue(candidate)) == 0 && !slices.Contains(values, candidate) {
+			values = append(values, candidate)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	profiles, err := s.listProfiles(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s in (%s)", s.profileOwnerLabel, strings.Join(values, ",")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]profileNamespace, 0, len(profiles.Items))
+	for i := range profiles.Items {
+		if ref := s.newProfileNamespace(&profiles.Items[i]); ref.Namespace != "" {
+			namespaces = append(namespaces, ref)
+		}
+	}
+	sortProfileNamespaces(namespaces)
+	return namespaces, nil
+}
+
+// listProfiles lists Profiles with the admin client behind the circuit
+// breaker.
+func (s *server) listProfiles(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	probe, err := s.adminBreaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := s.adminDynamic.Resource(s.profileGVR).List(ctx, opts)
+	s.adminBreaker.record(err, probe)
+	return profiles, err
+}
+
 func canListManagedSecrets(ctx context.Context, impClient kubernetes.Interface, namespace string) (bool, error) {
 	_, err := impClient.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
 		Limit:         1,
@@ -92,6 +272,13 @@ func (s *server) identityFromRequest(r *http.Request) (string, []string, error)
 	return user, normalizeGroups(r.Header.Values(s.groupsHeader)), nil
 }
 
+func (s *server) legacyUserFromRequest(r *http.Request) string {
+	if s.legacyUserHeader == "" {
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get(s.legacyUserHeader))
+}
+
 func (s *server) newImpersonatedClient(user string, groups []string) (kubernetes.Interface, error) {
 	cfg := rest.CopyConfig(s.baseConfig)
 	cfg.Impersonate = rest.ImpersonationConfig{
@@ -125,7 +312,27 @@ func normalizeIdentity(v string) string {
 	return strings.ToLower(sanitizeForLog(v))
 }
 
-func identityCandidates(v string) []string {
+// identityCandidates extends identityCandidatesFor with the
+// IDENTITY_NORMALIZE_REGEX capture, so a value such as "CN=alice,OU=eng" can
+// also match as "alice".
+func (s *server) identityCandidates(v string) []string {
+	candidates := identityCandidatesFor(v)
+	if s.identityNormalize == nil || len(candidates) == 0 {
+		return candidates
+	}
+
+	match := s.identityNormalize.FindStringSubmatch(sanitizeForLog(v))
+	if match == nil {
+		return candidates
+	}
+	captured := normalizeIdentity(match[s.identityNormalize.SubexpIndex(identityCaptureGroup)])
+	if captured == "" || slices.Contains(candidates, captured) {
+		return candidates
+	}
+	return append(candidates, captured)
+}
+
+func identityCandidatesFor(v string) []string {
 	normalized := normalizeIdentity(v)
 	if normalized == "" {
 		return nil
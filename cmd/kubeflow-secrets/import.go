@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// handleSecretImport serves POST /api/secrets:import, the counterpart of
+// exportZip: a multi-document YAML (or JSON) stream of Secret manifests,
+// each created in the caller's namespace. The whole body is read, split and
+// parsed before any secret is created, so size and count limits fail the
+// request up front instead of leaving a partial import behind.
+func (s *server) handleSecretImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userNamespace, impClient, ok := s.userContext(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := s.readImportBody(r)
+	if err != nil {
+		if errors.Is(err, errImportTooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("import body exceeds %d bytes", s.importMaxBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	documents, err := splitYAMLDocuments(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(documents) == 0 {
+		writeError(w, http.StatusBadRequest, "import contains no documents")
+		return
+	}
+	if int64(len(documents)) > s.importMaxDocuments {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("import has %d documents; at most %d are allowed", len(documents), s.importMaxDocuments))
+		return
+	}
+
+	policy, err := s.policyForNamespace(r.Context(), userNamespace)
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+
+	s.runBulk(w, r, "import", len(documents), func(r *http.Request, progress func()) (int, any) {
+		resp := secretImportResponse{Namespace: userNamespace, bulkResult: newBulkResult(len(documents))}
+		for i, document := range documents {
+			result := s.importDocument(r, impClient, userNamespace, policy, document)
+			result.Document = i + 1
+			resp.add(result)
+			progress()
+		}
+
+		logSafef("secrets imported: namespace=%q created=%d failed=%d", userNamespace, resp.Succeeded, resp.Failed)
+		return resp.status(), resp
+	})
+}
+
+var errImportTooLarge = errors.New("import body too large")
+
+func (s *server) readImportBody(r *http.Request) ([]byte, error) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			logSafef("failed to close request body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.importMaxBytes+1))
+	if err != nil {
+		return nil, errReadRequestBody
+	}
+	if int64(len(body)) > s.importMaxBytes {
+		return nil, errImportTooLarge
+	}
+	return body, nil
+}
+
+// splitYAMLDocuments splits on `---` separators and drops empty documents.
+func splitYAMLDocuments(body []byte) ([][]byte, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(body)))
+	documents := make([][]byte, 0)
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, errors.New("import is not valid YAML")
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		documents = append(documents, document)
+	}
+}
+
+// importDocument creates one secret through the same validation as
+// POST /api/secrets, bounded by IMPORT_DOCUMENT_TIMEOUT.
+func (s *server) importDocument(r *http.Request, client kubernetes.Interface, namespace string, policy namespacePolicy, document []byte) bulkItemResult {
+	var manifest corev1.Secret
+	if err := yaml.UnmarshalStrict(document, &manifest); err != nil {
+		return bulkItemResult{Status: http.StatusBadRequest, Error: "document is not a valid Secret manifest"}
+	}
+	result := bulkItemResult{Name: manifest.Name}
+	if manifest.Kind != "" && manifest.Kind != "Secret" {
+		result.Status, result.Error = http.StatusBadRequest, fmt.Sprintf("kind %q is not Secret", manifest.Kind)
+		return result
+	}
+	if manifest.Namespace != "" && manifest.Namespace != namespace {
+		result.Status, result.Error = http.StatusForbidden, "cross-namespace access is not allowed"
+		return result
+	}
+
+	req := manifestToUpsertRequest(&manifest, namespace)
+	secret, _, err := s.validateAndBuildSecret(req, policy)
+	if err != nil {
+		result.Status, result.Error = http.StatusBadRequest, err.Error()
+		return result
+	}
+	secret.Immutable = manifest.Immutable
+	s.stampCreator(r, secret)
+	s.stampProvenance(r, secret, true)
+	s.stampVersion(secret)
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.importDocumentTimeout)
+	defer cancel()
+	unlock := s.lockSecret(secret.Namespace, secret.Name)
+	defer unlock()
+
+	created, err := client.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if err != nil {
+		result.Status, result.Error = mapKubeError(err, "failed to create secret")
+		if isQuotaRejection(err) {
+			result.Status, result.Code, result.Error = http.StatusForbidden, errorCodeQuotaExceeded, sanitizeSingleLine(err.Error())
+		}
+		return result
+	}
+	s.writes.noteWrite(namespace)
+	logSafef("secret created: namespace=%q name=%q type=%q source=import%s", created.Namespace, created.Name, created.Type, s.keyNamesLogField(created))
+	result.Status = http.StatusCreated
+	return result
+}
+
+func manifestToUpsertRequest(manifest *corev1.Secret, namespace string) secretUpsertRequest {
+	data := make(map[string]string, len(manifest.Data))
+	for key, value := range manifest.Data {
+		data[key] = base64.StdEncoding.EncodeToString(value)
+	}
+	annotations := copyStringMap(manifest.Annotations)
+	for _, key := range serverOwnedAnnotations {
+		delete(annotations, key)
+	}
+	delete(annotations, versionAnnotation)
+	delete(annotations, lastAppliedAnnotation)
+
+	return secretUpsertRequest{
+		Namespace:   namespace,
+		Name:        strings.TrimSpace(manifest.Name),
+		Type:        manifest.Type,
+		Data:        data,
+		StringData:  manifest.StringData,
+		Labels:      ensureManagedLabels(manifest.Labels),
+		Annotations: annotations,
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sync"
+)
+
+// secretLocker serializes writes to the same secret within this process when
+// WRITE_LOCK_ENABLED is set. It does not coordinate across replicas; the
+// apiserver's resourceVersion check remains the cluster-wide guard.
+type secretLocker struct {
+	mu    sync.Mutex
+	locks map[string]*refCountedMutex
+}
+
+type refCountedMutex struct {
+	sync.Mutex
+	refs int
+}
+
+func newSecretLocker() *secretLocker {
+	return &secretLocker{locks: make(map[string]*refCountedMutex)}
+}
+
+// lock blocks until the (namespace, name) lock is held and returns its
+// release function. Entries are dropped once no goroutine references them.
+func (l *secretLocker) lock(namespace, name string) func() {
+	key := namespace + "/" + name
+
+	l.mu.Lock()
+	m, ok := l.locks[key]
+	if !ok {
+		m = &refCountedMutex{}
+		l.locks[key] = m
+	}
+	m.refs++
+	l.mu.Unlock()
+
+	m.Lock()
+	return func() {
+		m.Unlock()
+
+		l.mu.Lock()
+		m.refs--
+		if m.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (s *server) lockSecret(namespace, name string) func() {
+	if s.writeLocks == nil {
+		return func() {}
+	}
+	return s.writeLocks.lock(namespace, name)
+}
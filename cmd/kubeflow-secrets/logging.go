@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// requestLog is the access log entry withLogging writes once per request.
+type requestLog struct {
+	Time       string  `json:"time"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Remote     string  `json:"remote"`
+	ClientIP   string  `json:"client_ip"`
+	User       string  `json:"user"`
+	RequestID  string  `json:"request_id"`
+
+	duration time.Duration
+}
+
+// newRequestLogger picks the access log encoder for LOG_FORMAT once, at
+// startup. Only access logs change format; other log lines stay text.
+func newRequestLogger(format string) (func(entry requestLog), error) {
+	switch format {
+	case logFormatText:
+		return logRequestText, nil
+	case logFormatJSON:
+		logger := log.New(log.Writer(), "", 0)
+		return func(entry requestLog) { logRequestJSON(logger, entry) }, nil
+	default:
+		return nil, fmt.Errorf("LOG_FORMAT must be %q or %q, got %q", logFormatText, logFormatJSON, format)
+	}
+}
+
+func logRequestText(entry requestLog) {
+	logSafef(
+		"request method=%s path=%s status=%d duration=%s remote=%s client_ip=%q user=%q request_id=%q",
+		entry.Method,
+		entry.Path,
+		entry.Status,
+		entry.duration.String(),
+		entry.Remote,
+		entry.ClientIP,
+		entry.User,
+		entry.RequestID,
+	)
+}
+
+// logRequestJSON writes entry as one JSON object per line. User-controlled
+// values get the same single-line sanitizing as text logs before encoding.
+func logRequestJSON(logger *log.Logger, entry requestLog) {
+	entry.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	entry.DurationMS = float64(entry.duration.Microseconds()) / 1000
+	for _, field := range []*string{&entry.Method, &entry.Path, &entry.Remote, &entry.ClientIP, &entry.User, &entry.RequestID} {
+		*field = sanitizeSingleLine(*field)
+	}
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		logSafef("failed to encode request log: %v", err)
+		return
+	}
+	logger.Print(string(encoded))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// handleSecretByUID serves GET /api/secrets:byUid?uid=..., a stable handle
+// that survives a secret being deleted and recreated under the same name.
+// Secrets have no uid field selector, so each of the caller's namespaces is
+// listed in turn; namespaces the caller cannot list are skipped.
+func (s *server) handleSecretByUID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	uid := types.UID(strings.TrimSpace(r.URL.Query().Get("uid")))
+	if uid == "" {
+		writeError(w, http.StatusBadRequest, "uid is required")
+		return
+	}
+
+	user, groups, err := s.identityFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	impClient, err := s.newImpersonatedClient(user, groups)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create Kubernetes client")
+		return
+	}
+	namespaces, err := s.resolveUserNamespaces(r.Context(), user, groups, s.legacyUserFromRequest(r))
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+
+	for _, namespace := range namespaces {
+		secrets, err := listSecretsBySelector(r.Context(), impClient, namespace, managedLabelSelector())
+		if err != nil {
+			if apierrors.IsForbidden(err) {
+				continue
+			}
+			status, msg := mapKubeError(err, "failed to look up secret")
+			logSafef("secret uid lookup failed: namespace=%q status=%d err=%v", namespace, status, err)
+			writeError(w, status, msg)
+			return
+		}
+		for i := range secrets {
+			if secrets[i].UID == uid && isManagedSecret(&secrets[i]) {
+				w.Header().Set(resolvedNamespaceHeader, namespace)
+				writeJSON(w, http.StatusOK, s.secretDetail(r, &secrets[i]))
+				return
+			}
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "no managed secret with that uid in your namespaces")
+}
+
+// handleSecretsByKey serves GET /api/secrets:byKey?key=..., the managed
+// secrets in the caller's namespace that hold a data key, for users who
+// remember a key name but not which secret has it. Secrets are listed with
+// their data, but only names and types are returned.
+func (s *server) handleSecretsByKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	if key == "" {
+		writeError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid key: %s", strings.Join(errs, ", ")))
+		return
+	}
+
+	userNamespace, impClient, ok := s.userContext(w, r)
+	if !ok {
+		return
+	}
+	secrets, err := listSecretsBySelector(r.Context(), impClient, userNamespace, managedLabelSelector())
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to list secrets")
+		logSafef("secret key lookup failed: namespace=%q status=%d err=%v", userNamespace, status, err)
+		writeError(w, status, msg)
+		return
+	}
+
+	resp := secretsByKeyResponse{Namespace: userNamespace, Key: key, Items: make([]secretKeyMatch, 0)}
+	for i := range secrets {
+		if _, found := secrets[i].Data[key]; found && isManagedSecret(&secrets[i]) {
+			resp.Items = append(resp.Items, secretKeyMatch{Name: secrets[i].Name, Type: secrets[i].Type, Hidden: isHiddenSecret(&secrets[i])})
+		}
+	}
+	sort.Slice(resp.Items, func(i, j int) bool { return resp.Items[i].Name < resp.Items[j].Name })
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleSecretNameAvailable serves GET /api/secrets/{name}:available for
+// inline form validation. Any secret counts as taken, managed or not, since
+// a create would collide with either; nothing else about it is returned.
+func (s *server) handleSecretNameAvailable(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
+	resp := secretNameAvailableResponse{Name: secretName, Namespace: userNamespace}
+	_, err := impClient.CoreV1().Secrets(userNamespace).Get(r.Context(), secretName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		resp.Available = true
+	case err != nil:
+		status, msg := mapKubeError(err, "failed to check secret name")
+		writeError(w, status, msg)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"io/fs"
 	"log"
@@ -12,39 +13,76 @@ import (
 //go:embed static/*
 var staticFS embed.FS
 
+// version is the build version, set with -ldflags "-X main.version=...".
+var version = "dev"
+
 const readHeaderTimeout = 10 * time.Second
 
 func main() {
 	addr := envOrDefault("LISTEN_ADDR", ":8080")
-	userHeader := envOrDefault("USER_HEADER", "kubeflow-userid")
-	groupsHeader := envOrDefault("GROUPS_HEADER", "kubeflow-groups")
+
+	opts, err := loadServerOptions()
+	if err != nil {
+		log.Fatalf("load server options: %v", err)
+	}
 
 	cfg, err := buildKubeConfig()
 	if err != nil {
 		log.Fatalf("build kube config: %v", err)
 	}
 
-	srv, err := newServer(cfg, userHeader, groupsHeader)
+	srv, err := newServer(cfg, opts)
 	if err != nil {
 		log.Fatalf("create server: %v", err)
 	}
+	log.Printf("kubeflow-secrets %s starting", version)
+
+	if opts.companionGCInterval > 0 {
+		go srv.runCompanionSweep(context.Background(), opts.companionGCInterval)
+	}
 
 	routes := http.NewServeMux()
 	routes.HandleFunc("/healthz", srv.handleHealthz)
+	routes.HandleFunc("/readyz", srv.withJSON(srv.handleReadyz))
+	if opts.metricsEnabled {
+		routes.Handle(metricsPath, srv.metrics.handler())
+	}
+	routes.HandleFunc("/api/features", srv.withJSON(srv.handleFeatures))
+	routes.HandleFunc("/api/diagnostics", srv.withJSON(srv.handleDiagnostics))
+	routes.HandleFunc("/api/stats", srv.withJSON(srv.handleStats))
 	routes.HandleFunc("/api/namespaces", srv.withJSON(srv.handleNamespaces))
+	routes.HandleFunc("/api/namespaces/", srv.withJSON(srv.handleNamespacePolicy))
 	routes.HandleFunc("/api/secrets", srv.withJSON(srv.handleSecrets))
 	routes.HandleFunc("/api/secrets/", srv.withJSON(srv.handleSecretByName))
+	routes.HandleFunc("/api/secrets:byUid", srv.withJSON(srv.handleSecretByUID))
+	routes.HandleFunc("/api/secrets:byKey", srv.withJSON(srv.handleSecretsByKey))
+	routes.HandleFunc("/api/secrets:exportZip", srv.withJSON(srv.handleSecretExportZip))
+	routes.HandleFunc("/api/secrets:import", srv.withJSON(srv.handleSecretImport))
+	routes.HandleFunc("/api/secrets:batchCreate", srv.withJSON(srv.handleSecretBatchCreate))
+	routes.HandleFunc("/api/secrets:recent", srv.withJSON(srv.handleRecentSecrets))
+	routes.HandleFunc("/api/operations/", srv.withJSON(srv.handleOperation))
+	routes.HandleFunc("/api/admin/migrate-labels", srv.withJSON(srv.handleAdminMigrateLabels))
+	routes.HandleFunc("/api/admin/gc", srv.withJSON(srv.handleAdminGC))
+	routes.HandleFunc("/api/admin/resolve", srv.withJSON(srv.handleAdminResolve))
 
-	staticSub, err := fs.Sub(staticFS, "static")
-	if err != nil {
-		log.Fatalf("prepare embedded static assets: %v", err)
+	if opts.serveUI {
+		staticSub, err := fs.Sub(staticFS, "static")
+		if err != nil {
+			log.Fatalf("prepare embedded static assets: %v", err)
+		}
+		var ui http.Handler = http.FileServer(http.FS(staticSub))
+		if opts.uiFallbackPage {
+			ui = srv.withUIFallback(ui)
+		}
+		routes.Handle("/", ui)
+	} else {
+		routes.HandleFunc("/", srv.withJSON(handleNotFound))
 	}
-	routes.Handle("/", http.FileServer(http.FS(staticSub)))
 
 	log.Printf("starting secrets API on %s", addr)
 	httpServer := &http.Server{
 		Addr:              addr,
-		Handler:           srv.withLogging(routes),
+		Handler:           srv.withLogging(srv.withTracing(srv.withMetrics(routes))),
 		ReadHeaderTimeout: readHeaderTimeout,
 	}
 
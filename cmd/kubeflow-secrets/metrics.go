@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Request metrics live in their own registry rather than the Prometheus
+// default one: a request counter by method, route template and status code,
+// and a duration histogram by method and route template. Routes are ServeMux
+// patterns refined into templates such as /api/secrets/{name}, so secret
+// names, keys and namespaces never become label values.
+
+const (
+	metricsPath = "/metrics"
+
+	requestsTotalMetric   = "kubeflow_secrets_http_requests_total"
+	requestDurationMetric = "kubeflow_secrets_http_request_duration_seconds"
+)
+
+type metrics struct {
+	registry  *prometheus.Registry
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: requestsTotalMetric,
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "code"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    requestDurationMetric,
+			Help:    "HTTP request duration by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+	m.registry.MustRegister(m.requests, m.durations)
+	return m
+}
+
+func (m *metrics) observe(method, route string, code int, elapsed time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	m.durations.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// handler serves the registry in the Prometheus exposition format.
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// withMetrics records every request except probes and scrapes. It wraps the
+// mux itself because the matched pattern is what bounds the route label.
+func (s *server) withMetrics(routes *http.ServeMux) http.Handler {
+	if s.metrics == nil {
+		return routes
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" || r.URL.Path == metricsPath {
+			routes.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		_, pattern := routes.Handler(r)
+		routes.ServeHTTP(rec, r)
+		s.metrics.observe(metricMethod(r.Method), routeTemplate(pattern, r.URL.Path), rec.status, time.Since(start))
+	})
+}
+
+// metricMethod folds unknown methods into one value; the method is client
+// input and would otherwise be an unbounded label.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// routeTemplate turns the matched ServeMux pattern into a template. Exact
+// patterns are used as they are; subtree patterns that carry names in the
+// path are refined from the path's shape, never its values.
+func routeTemplate(pattern, path string) string {
+	switch pattern {
+	case secretsPathPrefix:
+		name, subresource, _, err := parseSecretPath(path)
+		switch {
+		case path == secretsPathPrefix:
+			return secretsPathPrefix
+		case err != nil || name == "":
+			return secretsPathPrefix + "{invalid}"
+		case subresource == "":
+			return secretsPathPrefix + "{name}"
+		case subresource == secretSubresourceDownload:
+			return secretsPathPrefix + "{name}/" + secretKeysSegment + "/{key}/" + secretSubresourceDownload
+		case subresource == secretSubresourceEvents || subresource == secretSubresourceYAML || subresource == secretSubresourceDotenv:
+			return secretsPathPrefix + "{name}/" + subresource
+		default:
+			return secretsPathPrefix + "{name}:" + subresource
+		}
+	case namespacesPathPrefix:
+		if strings.HasSuffix(path, "/policy") {
+			return namespacesPathPrefix + "{namespace}/policy"
+		}
+		return namespacesPathPrefix + "{namespace}"
+	case operationsPathPrefix:
+		return operationsPathPrefix + "{id}"
+	case "":
+		return "unmatched"
+	default:
+		return pattern
+	}
+}
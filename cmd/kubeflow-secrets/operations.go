@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAsyncWorkers   = 4
+	defaultAsyncQueueSize = 16
+	defaultAsyncResultTTL = 15 * time.Minute
+	defaultAsyncPerUser   = 2
+
+	// operationRetryAfter is the Retry-After, in seconds, sent when an
+	// operation cannot be queued. Bulk items take well under a second each,
+	// so a slot usually frees up within a few seconds.
+	operationRetryAfter = "5"
+
+	asyncQueryParam      = "async"
+	operationsPathPrefix = "/api/operations/"
+
+	operationQueued    = "queued"
+	operationRunning   = "running"
+	operationSucceeded = "succeeded"
+	operationFailed    = "failed"
+)
+
+// operationStore runs bulk requests sent with ?async=true on a fixed pool of
+// ASYNC_WORKERS goroutines and keeps each result for ASYNC_RESULT_TTL after
+// it finishes. Like the write tracker it only knows this pod's operations,
+// so clients polling one need session affinity.
+//
+// The queue is shared, so each user may only have ASYNC_MAX_PER_USER
+// operations queued or running at once. One tenant's large imports then
+// wait on their own share instead of filling every slot ahead of others,
+// and since the share is below ASYNC_WORKERS, at least one worker is always
+// left for other users.
+type operationStore struct {
+	mu      sync.Mutex
+	ops     map[string]*operation
+	queue   chan *operation
+	ttl     time.Duration
+	perUser int
+}
+
+var (
+	errOperationQueueFull = errors.New("too many queued operations, retry shortly")
+	errOperationUserLimit = errors.New("you already have the maximum number of operations in progress, retry when one finishes")
+)
+
+// operation is one queued request. resp is guarded by the store's mutex and
+// copied out on every read.
+type operation struct {
+	owner string
+	run   func(progress func()) (int, any)
+	resp  operationResponse
+}
+
+func newOperationStore(workers, queueSize, perUser int64, ttl time.Duration) *operationStore {
+	store := &operationStore{
+		ops:     make(map[string]*operation),
+		queue:   make(chan *operation, queueSize),
+		ttl:     ttl,
+		perUser: int(perUser),
+	}
+	for range workers {
+		go store.work()
+	}
+	return store
+}
+
+func (o *operationStore) work() {
+	for op := range o.queue {
+		o.execute(op)
+	}
+}
+
+// execute runs one operation. A panic fails the operation instead of the
+// whole process, since no net/http recovery covers this goroutine.
+func (o *operationStore) execute(op *operation) {
+	o.update(op, func(resp *operationResponse) {
+		now := time.Now()
+		resp.Status = operationRunning
+		resp.StartedAt = &now
+	})
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logSafef("async operation panicked: id=%q kind=%q err=%v", op.resp.ID, op.resp.Kind, recovered)
+			o.update(op, func(resp *operationResponse) {
+				now := time.Now()
+				resp.Status = operationFailed
+				resp.HTTPStatus = http.StatusInternalServerError
+				resp.Error = "operation failed unexpectedly"
+				resp.FinishedAt = &now
+			})
+		}
+	}()
+
+	status, result := op.run(func() {
+		o.update(op, func(resp *operationResponse) { resp.Completed++ })
+	})
+	o.update(op, func(resp *operationResponse) {
+		now := time.Now()
+		resp.Status = operationSucceeded
+		resp.HTTPStatus = status
+		resp.Result = result
+		resp.FinishedAt = &now
+	})
+}
+
+func (o *operationStore) update(op *operation, fn func(resp *operationResponse)) {
+	o.mu.Lock()
+	fn(&op.resp)
+	o.mu.Unlock()
+}
+
+// submit queues run for owner. It fails instead of blocking the request
+// that asked when owner's share or the whole queue is used up.
+func (o *operationStore) submit(owner, kind string, total int, run func(progress func()) (int, any)) (operationResponse, error) {
+	id, err := newOperationID()
+	if err != nil {
+		return operationResponse{}, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.prune(time.Now())
+	if o.inProgress(owner) >= o.perUser {
+		return operationResponse{}, errOperationUserLimit
+	}
+	op := &operation{
+		owner: owner,
+		run:   run,
+		resp:  operationResponse{ID: id, Kind: kind, Status: operationQueued, Total: total, CreatedAt: time.Now()},
+	}
+	select {
+	case o.queue <- op:
+		o.ops[id] = op
+		return op.resp, nil
+	default:
+		return operationResponse{}, errOperationQueueFull
+	}
+}
+
+// inProgress counts owner's queued and running operations. Callers hold o.mu.
+func (o *operationStore) inProgress(owner string) int {
+	count := 0
+	for _, op := range o.ops {
+		if op.owner == owner && op.resp.FinishedAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// get returns the operation only to the identity that started it.
+func (o *operationStore) get(owner, id string) (operationResponse, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.prune(time.Now())
+	op, ok := o.ops[id]
+	if !ok || op.owner != owner {
+		return operationResponse{}, false
+	}
+	return op.resp, true
+}
+
+// prune drops finished operations older than the TTL. Callers hold o.mu.
+func (o *operationStore) prune(now time.Time) {
+	for id, op := range o.ops {
+		if op.resp.FinishedAt != nil && now.Sub(*op.resp.FinishedAt) > o.ttl {
+			delete(o.ops, id)
+		}
+	}
+}
+
+func newOperationID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// runBulk runs a bulk request inline, or, when the caller asked for
+// ?async=true, on the operation pool and answers 202 with the operation to
+// poll. run gets a request detached from the caller's cancellation, because
+// the caller is expected to hang up once it has the operation ID.
+func (s *server) runBulk(w http.ResponseWriter, r *http.Request, kind string, total int, run func(r *http.Request, progress func()) (int, any)) {
+	if r.URL.Query().Get(asyncQueryParam) != "true" {
+		status, resp := run(r, func() {})
+		writeJSON(w, status, resp)
+		return
+	}
+	if s.operations == nil {
+		writeError(w, http.StatusBadRequest, "async operations are disabled on this server")
+		return
+	}
+
+	user, _, err := s.identityFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	detached := r.WithContext(context.WithoutCancel(r.Context()))
+	op, err := s.operations.submit(normalizeIdentity(user), kind, total, func(progress func()) (int, any) {
+		return run(detached, progress)
+	})
+	switch {
+	case errors.Is(err, errOperationUserLimit):
+		w.Header().Set("Retry-After", operationRetryAfter)
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	case errors.Is(err, errOperationQueueFull):
+		w.Header().Set("Retry-After", operationRetryAfter)
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to queue operation")
+		return
+	}
+
+	logSafef("async operation queued: id=%q kind=%q total=%d", op.ID, kind, total)
+	w.Header().Set("Location", operationsPathPrefix+op.ID)
+	writeJSON(w, http.StatusAccepted, op)
+}
+
+// handleOperation serves GET /api/operations/{id}. Operations of other users
+// answer 404 like unknown or expired ones, so IDs cannot be probed.
+func (s *server) handleOperation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, operationsPathPrefix)
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	user, _, err := s.identityFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if s.operations == nil {
+		writeError(w, http.StatusNotFound, "async operations are disabled on this server")
+		return
+	}
+
+	op, ok := s.operations.get(normalizeIdentity(user), id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("operation %q not found or expired", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, op)
+}
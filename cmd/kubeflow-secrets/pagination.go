@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultSortedPaginationMax = 500
+	defaultListMaxLimit        = 1000
+	defaultCursorTTL           = time.Hour
+
+	listSortName         = "name"
+	listSortModified     = "modified"
+	listSortModifiedDesc = "-modified"
+
+	cursorModeSorted = "s"
+	cursorModeKube   = "k"
+	cursorVersion    = 1
+	cursorMACSize    = 16
+)
+
+var (
+	errInvalidListLimit    = errors.New("limit must be a positive integer")
+	errInvalidContinue     = errors.New("invalid continue token; restart from the first page")
+	errExpiredContinue     = errors.New("continue token expired; restart from the first page")
+	errContinueMismatch    = errors.New("continue token belongs to a different namespace or filter set; restart from the first page")
+	errListLimitOutOfRange = errors.New("limit exceeds maximum page size")
+	errContinueNeedsLimit  = errors.New("continue requires limit")
+	// Apiserver pages come in storage order, so they cannot be cut by
+	// modification time.
+	errSortNeedsSortedPages = errors.New("sort=modified cannot be paged in namespaces with more than SORTED_PAGINATION_MAX managed secrets")
+)
+
+// listCursor is the server-side state behind a continue token. Clients get
+// it base64-encoded and HMAC-signed, so they cannot edit the resume point
+// and a token only resumes the namespace and filters it was issued for.
+//
+//nolint:tagliatelle // Compact keys keep tokens short; the format is opaque to clients.
+type listCursor struct {
+	Version   int    `json:"v"`
+	Mode      string `json:"m"`
+	Namespace string `json:"ns"`
+	Scope     string `json:"f"`
+	After     string `json:"a"`
+	IssuedAt  int64  `json:"t"`
+}
+
+type listPage struct {
+	limit         int64
+	continueToken string
+	// scope identifies the filters of the scan (selector, sort, mine, ...).
+	// Tokens issued for one scope are rejected for another, and shared reads
+	// only ever share a page within one scope.
+	scope string
+	// order is the sort pages are cut by (name, modified or -modified).
+	order string
+	// fieldSelector narrows every list call on the apiserver (type=...).
+	fieldSelector string
+	// keep drops items the apiserver cannot filter out (e.g. mine=true)
+	// before a sorted page is cut. nil keeps everything.
+	keep func(*corev1.Secret) bool
+}
+
+// parseListPage reads limit and continue. Requests without a limit get
+// LIST_DEFAULT_LIMIT, where 0 keeps the unpaged list; explicit limits above
+// LIST_MAX_LIMIT are rejected rather than silently cut.
+func (s *server) parseListPage(r *http.Request) (listPage, error) {
+	query := r.URL.Query()
+	page := listPage{continueToken: strings.TrimSpace(query.Get("continue")), limit: s.listDefaultLimit}
+
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit <= 0 {
+			return listPage{}, errInvalidListLimit
+		}
+		if limit > s.listMaxLimit {
+			return listPage{}, errListLimitOutOfRange
+		}
+		page.limit = limit
+	}
+	if page.continueToken != "" && page.limit == 0 {
+		return listPage{}, errContinueNeedsLimit
+	}
+	return page, nil
+}
+
+// listManagedSecretPage returns one page of secrets matching selector, which
+// callers build on top of managedLabelSelector, and the token for the next
+// page. Without a limit, everything is returned in one response.
+//
+// Pages only carry metadata and data key names, which is all the list view
+// reads. The apiserver cannot list key names without their values, so values
+// still arrive with each list call, but they are dropped before the page is
+// cut, cached or shared.
+//
+// With a limit, namespaces holding at most sortedPaginationMax managed
+// secrets are fetched in full, sorted by page.order and sliced in memory, so
+// pages are globally ordered and resuming after the last seen position
+// tolerates concurrent creates/deletes. Larger namespaces fall back to the
+// apiserver's continue tokens; that keeps memory bounded but the name sort
+// only applies within a page, since the apiserver does not return items in
+// name order across pages, and a modified sort is refused. page.keep runs on
+// each apiserver page there, so those pages can come back short.
+//
+// Either way the client only sees a signed listCursor, never a raw apiserver
+// token, and a cursor is only honoured for the namespace and scope it was
+// issued for and within CURSOR_TTL.
+func (s *server) listManagedSecretPage(ctx context.Context, client kubernetes.Interface, namespace, selector string, page listPage) ([]corev1.Secret, string, error) {
+	if page.limit == 0 {
+		all, err := listSecretKeys(ctx, client, namespace, selector, page.fieldSelector)
+		return page.filter(all), "", err
+	}
+
+	cursor, err := s.decodeCursor(page.continueToken, namespace, page.scope)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch cursor.Mode {
+	case cursorModeKube:
+		return s.listKubePage(ctx, client, namespace, selector, page, cursor.After)
+	case cursorModeSorted:
+		all, err := listSecretKeys(ctx, client, namespace, selector, page.fieldSelector)
+		if err != nil {
+			return nil, "", err
+		}
+		items, last, err := sortedPage(page.filter(all), page.limit, page.order, cursor.After)
+		if err != nil {
+			return nil, "", err
+		}
+		return items, s.encodeCursor(cursorModeSorted, namespace, page.scope, last), nil
+	}
+
+	if s.sortedPaginationMax > 0 {
+		list, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector,
+			FieldSelector: page.fieldSelector,
+			Limit:         s.sortedPaginationMax,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if list.Continue == "" {
+			items, last, err := sortedPage(page.filter(metadataOnly(list.Items)), page.limit, page.order, "")
+			if err != nil {
+				return nil, "", err
+			}
+			return items, s.encodeCursor(cursorModeSorted, namespace, page.scope, last), nil
+		}
+	}
+	if page.order == listSortModified || page.order == listSortModifiedDesc {
+		return nil, "", errSortNeedsSortedPages
+	}
+
+	return s.listKubePage(ctx, client, namespace, selector, page, "")
+}
+
+func (s *server) listKubePage(ctx context.Context, client kubernetes.Interface, namespace, selector string, page listPage, kubeContinue string) ([]corev1.Secret, string, error) {
+	list, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+		FieldSelector: page.fieldSelector,
+		Limit:         page.limit,
+		Continue:      kubeContinue,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return page.filter(metadataOnly(list.Items)), s.encodeCursor(cursorModeKube, namespace, page.scope, list.Continue), nil
+}
+
+// filter applies keep in place.
+func (p listPage) filter(secrets []corev1.Secret) []corev1.Secret {
+	if p.keep == nil {
+		return secrets
+	}
+	kept := secrets[:0]
+	for i := range secrets {
+		if p.keep(&secrets[i]) {
+			kept = append(kept, secrets[i])
+		}
+	}
+	return kept
+}
+
+func listSecretsBySelector(ctx context.Context, client kubernetes.Interface, namespace, selector string) ([]corev1.Secret, error) {
+	list, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// listSecretKeys lists secrets for the list view: values are dropped right
+// after the call, leaving metadata and key names.
+func listSecretKeys(ctx context.Context, client kubernetes.Interface, namespace, selector, fieldSelector string) ([]corev1.Secret, error) {
+	list, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector, FieldSelector: fieldSelector})
+	if err != nil {
+		return nil, err
+	}
+	return metadataOnly(list.Items), nil
+}
+
+// metadataOnly copies secrets keeping their data keys but dropping values.
+func metadataOnly(secrets []corev1.Secret) []corev1.Secret {
+	out := make([]corev1.Secret, len(secrets))
+	for i := range secrets {
+		out[i] = corev1.Secret{
+			TypeMeta:   secrets[i].TypeMeta,
+			ObjectMeta: *secrets[i].ObjectMeta.DeepCopy(),
+			Type:       secrets[i].Type,
+			Immutable:  secrets[i].Immutable,
+		}
+		if len(secrets[i].Data) > 0 {
+			out[i].Data = make(map[string][]byte, len(secrets[i].Data))
+			for key := range secrets[i].Data {
+				out[i].Data[key] = nil
+			}
+		}
+	}
+	return out
+}
+
+// sortedPage sorts secrets by order and returns the page after the position
+// after, plus the position of the last item when more items follow, or ""
+// on the final page.
+func sortedPage(secrets []corev1.Secret, limit int64, order, after string) ([]corev1.Secret, string, error) {
+	positions := make(map[string]listPosition, len(secrets))
+	for i := range secrets {
+		positions[secrets[i].Name] = positionOf(&secrets[i])
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return positions[secrets[i].Name].before(positions[secrets[j].Name], order)
+	})
+
+	start := 0
+	if after != "" {
+		from, err := parseListPosition(after, order)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(secrets), func(i int) bool {
+			return from.before(positions[secrets[i].Name], order)
+		})
+	}
+	end := min(start+int(limit), len(secrets))
+
+	last := ""
+	if end < len(secrets) {
+		last = positions[secrets[end-1].Name].encode(order)
+	}
+	return secrets[start:end], last, nil
+}
+
+// listPosition is where an item sits in a sorted scan. Sorted continue
+// tokens carry it, so the next page resumes after the same key the page was
+// cut by.
+type listPosition struct {
+	modified time.Time
+	name     string
+}
+
+func positionOf(secret *corev1.Secret) listPosition {
+	return listPosition{modified: lastModifiedTime(secret), name: secret.Name}
+}
+
+func (p listPosition) before(other listPosition, order string) bool {
+	switch order {
+	case listSortModified:
+		if !p.modified.Equal(other.modified) {
+			return p.modified.Before(other.modified)
+		}
+	case listSortModifiedDesc:
+		if !p.modified.Equal(other.modified) {
+			return p.modified.After(other.modified)
+		}
+	}
+	return p.name < other.name
+}
+
+// encode renders the position for a cursor: the name alone for name order,
+// the modification time in nanoseconds and the name otherwise. Secret names
+// never contain "/".
+func (p listPosition) encode(order string) string {
+	if order != listSortModified && order != listSortModifiedDesc {
+		return p.name
+	}
+	return strconv.FormatInt(p.modified.UnixNano(), 10) + "/" + p.name
+}
+
+func parseListPosition(raw, order string) (listPosition, error) {
+	if order != listSortModified && order != listSortModifiedDesc {
+		return listPosition{name: raw}, nil
+	}
+	rawModified, name, ok := strings.Cut(raw, "/")
+	if !ok {
+		return listPosition{}, errInvalidContinue
+	}
+	modified, err := strconv.ParseInt(rawModified, 10, 64)
+	if err != nil {
+		return listPosition{}, errInvalidContinue
+	}
+	return listPosition{modified: time.Unix(0, modified), name: name}, nil
+}
+
+// sortListItems orders the items of a response. Sorted pages already come
+// in this order; it matters for unpaged lists and for the name order within
+// an apiserver page.
+func sortListItems(items []secretListItem, order string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		switch order {
+		case listSortModified:
+			if !items[i].LastModified.Equal(*items[j].LastModified) {
+				return items[i].LastModified.Before(*items[j].LastModified)
+			}
+		case listSortModifiedDesc:
+			if !items[i].LastModified.Equal(*items[j].LastModified) {
+				return items[i].LastModified.After(*items[j].LastModified)
+			}
+		}
+		return items[i].Name < items[j].Name
+	})
+}
+
+// encodeCursor signs a continue token; an empty after means no next page.
+func (s *server) encodeCursor(mode, namespace, scope, after string) string {
+	if after == "" {
+		return ""
+	}
+	payload, err := json.Marshal(listCursor{
+		Version:   cursorVersion,
+		Mode:      mode,
+		Namespace: namespace,
+		Scope:     scope,
+		After:     after,
+		IssuedAt:  time.Now().Unix(),
+	})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.cursorMAC(payload))
+}
+
+// decodeCursor verifies a continue token against the namespace and scope of
+// the current request. An empty token yields the zero cursor (first page).
+func (s *server) decodeCursor(token, namespace, scope string) (listCursor, error) {
+	if token == "" {
+		return listCursor{}, nil
+	}
+
+	rawPayload, rawMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return listCursor{}, errInvalidContinue
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(rawPayload)
+	if err != nil {
+		return listCursor{}, errInvalidContinue
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(rawMAC)
+	if err != nil || !hmac.Equal(mac, s.cursorMAC(payload)) {
+		return listCursor{}, errInvalidContinue
+	}
+
+	var cursor listCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.Version != cursorVersion || cursor.After == "" {
+		return listCursor{}, errInvalidContinue
+	}
+	if cursor.Mode != cursorModeSorted && cursor.Mode != cursorModeKube {
+		return listCursor{}, errInvalidContinue
+	}
+	if time.Since(time.Unix(cursor.IssuedAt, 0)) > s.cursorTTL {
+		return listCursor{}, errExpiredContinue
+	}
+	if cursor.Namespace != namespace || cursor.Scope != scope {
+		return listCursor{}, errContinueMismatch
+	}
+	return cursor, nil
+}
+
+func (s *server) cursorMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)[:cursorMACSize]
+}
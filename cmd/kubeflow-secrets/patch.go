@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// handleSecretPatch serves PATCH /api/secrets/{name}: labels, annotations
+// or single data keys change without resending the other values. The merged
+// result goes through the same validation and write path as PUT.
+func (s *server) handleSecretPatch(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
+	returnFull, err := parseReturnMode(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	patch, err := s.readPatchRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := checkManagedLabelPatch(patch.Labels); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	unlock := s.lockSecret(userNamespace, secretName)
+	defer unlock()
+
+	existing, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName)
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to update secret")
+		writeError(w, status, msg)
+		return
+	}
+
+	req := secretUpsertRequest{
+		Namespace:   userNamespace,
+		Name:        secretName,
+		Type:        existing.Type,
+		Labels:      mergePatchMap(existing.Labels, patch.Labels),
+		Annotations: mergePatchMap(existing.Annotations, patch.Annotations),
+		Data:        make(map[string]string, len(existing.Data)),
+		StringData:  make(map[string]string, len(patch.StringData)),
+	}
+	for key, value := range existing.Data {
+		req.Data[key] = base64.StdEncoding.EncodeToString(value)
+	}
+	for key, value := range patch.Data {
+		if value == nil {
+			delete(req.Data, key)
+			continue
+		}
+		req.Data[key] = *value
+	}
+	for key, value := range patch.StringData {
+		delete(req.Data, key)
+		if value != nil {
+			req.StringData[key] = *value
+		}
+	}
+
+	s.applySecretUpdate(w, r, impClient, existing, req, returnFull)
+}
+
+// readPatchRequest decodes the patch strictly: anything but the four maps
+// is rejected instead of silently ignored, so a patch meant for a field
+// this endpoint cannot change never looks like it succeeded.
+func (s *server) readPatchRequest(r *http.Request) (secretPatchRequest, error) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			logSafef("failed to close request body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.maxPayloadSize))
+	if err != nil {
+		return secretPatchRequest{}, errReadRequestBody
+	}
+
+	var patch secretPatchRequest
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return secretPatchRequest{}, fmt.Errorf("patch field %s is not supported; only labels, annotations, data and stringData can be patched", field)
+		}
+		return secretPatchRequest{}, errInvalidJSONInput
+	}
+	if key := invalidUTF8StringDataKey(body); key != "" {
+		return secretPatchRequest{}, invalidUTF8StringDataError(key)
+	}
+	return patch, nil
+}
+
+// checkManagedLabelPatch rejects patches that would drop or rewrite the
+// managed-by label. ensureManagedLabels would restore it anyway, but a
+// patch that asks for it should fail loudly instead of being half-applied.
+func checkManagedLabelPatch(labels map[string]*string) error {
+	for _, key := range []string{managedByLabelKey, legacyManagedByLabelKey} {
+		if key == "" {
+			continue
+		}
+		value, ok := labels[key]
+		if ok && (value == nil || *value != managedByLabelValue) {
+			return errors.New("the " + key + " label is set by the server and cannot be changed or removed")
+		}
+	}
+	return nil
+}
+
+// mergePatchMap applies one level of JSON merge patch to a string map.
+func mergePatchMap(base map[string]string, patch map[string]*string) map[string]string {
+	merged := copyStringMap(base)
+	if merged == nil {
+		merged = make(map[string]string, len(patch))
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = *value
+	}
+	return merged
+}
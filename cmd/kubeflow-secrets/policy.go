@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// profileAllowedTypesAnnotation lets a Profile narrow the secret types its
+// namespace accepts, as a comma-separated list. It can only restrict the
+// global allow list, never widen it.
+const profileAllowedTypesAnnotation = "kubeflow-secrets/allowed-types"
+
+// typeRequiredKeys lists the data keys a secret of the given type must carry.
+var typeRequiredKeys = map[corev1.SecretType][]string{
+	corev1.SecretTypeDockerConfigJson: {corev1.DockerConfigJsonKey},
+	corev1.SecretTypeTLS:              {corev1.TLSCertKey, corev1.TLSPrivateKeyKey},
+	corev1.SecretTypeSSHAuth:          {corev1.SSHAuthPrivateKey},
+}
+
+// typeAllowedKeys is the full key set of each well-known type, enforced
+// only with STRICT_TYPE_KEYS so consumers that assume a fixed schema never see
+// extra keys. Types not listed here accept any keys.
+var typeAllowedKeys = map[corev1.SecretType][]string{
+	corev1.SecretTypeDockerConfigJson: {corev1.DockerConfigJsonKey},
+	corev1.SecretTypeDockercfg:        {corev1.DockerConfigKey},
+	corev1.SecretTypeBasicAuth:        {corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey},
+	corev1.SecretTypeSSHAuth:          {corev1.SSHAuthPrivateKey},
+	corev1.SecretTypeTLS:              {corev1.TLSCertKey, corev1.TLSPrivateKeyKey, corev1.ServiceAccountRootCAKey},
+}
+
+// extraTypeKeys returns, sorted, the keys outside secretType's allowed set.
+func extraTypeKeys(secretType corev1.SecretType, data map[string][]byte, stringData map[string]string) []string {
+	allowed, known := typeAllowedKeys[secretType]
+	if !known {
+		return nil
+	}
+	extra := make([]string, 0)
+	for _, keys := range [][]string{slices.Collect(maps.Keys(data)), slices.Collect(maps.Keys(stringData))} {
+		for _, key := range keys {
+			if !slices.Contains(allowed, key) && !slices.Contains(extra, key) {
+				extra = append(extra, key)
+			}
+		}
+	}
+	slices.Sort(extra)
+	return extra
+}
+
+// missingRequiredKeys reports the keys a stored secret lacks for its type.
+// It only looks at key names, never values.
+func missingRequiredKeys(secret *corev1.Secret) []string {
+	missing := make([]string, 0)
+	for _, key := range typeRequiredKeys[secret.Type] {
+		if _, ok := secret.Data[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// namespacePolicy is the validation policy in effect for one namespace after
+// merging global configuration with Profile-level overrides.
+type namespacePolicy struct {
+	allowedTypes map[corev1.SecretType]struct{}
+}
+
+func (s *server) globalPolicy() namespacePolicy {
+	return namespacePolicy{allowedTypes: s.allowedTypes}
+}
+
+// policyForNamespace merges the global policy with the restrictions of the
+// Profiles that map to namespace. A namespace no Profile maps to gets no
+// policy at all, rather than silently falling back to the global one.
+func (s *server) policyForNamespace(ctx context.Context, namespace string) (namespacePolicy, error) {
+	profiles, err := s.profilesForNamespace(ctx, namespace)
+	if err != nil {
+		return namespacePolicy{}, err
+	}
+
+	policy := s.globalPolicy()
+	for _, profile := range profiles {
+		if raw, ok := profile.GetAnnotations()[profileAllowedTypesAnnotation]; ok {
+			policy.allowedTypes = intersectAllowedTypes(policy.allowedTypes, raw)
+		}
+	}
+	return policy, nil
+}
+
+// profilesForNamespace finds the Profiles whose namespace, as
+// newProfileNamespace maps it, is namespace. Without
+// PROFILE_NAMESPACE_FIELD_PATH that is the Profile of the same name. With it,
+// names and namespaces are unrelated, so Profiles are listed and mapped; if
+// several map to the namespace, all of their restrictions apply.
+func (s *server) profilesForNamespace(ctx context.Context, namespace string) ([]*unstructured.Unstructured, error) {
+	if len(s.profileNamespacePath) == 0 {
+		profile, err := s.getProfile(ctx, namespace)
+		if apierrors.IsNotFound(err) {
+			return nil, errProfileNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s.newProfileNamespace(profile).Namespace != namespace {
+			return nil, errProfileNotFound
+		}
+		return []*unstructured.Unstructured{profile}, nil
+	}
+
+	list, err := s.listProfiles(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*unstructured.Unstructured, 0, 1)
+	names := make([]string, 0, 1)
+	for i := range list.Items {
+		if ref := s.newProfileNamespace(&list.Items[i]); ref.Namespace == namespace {
+			matched = append(matched, &list.Items[i])
+			names = append(names, ref.Profile)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, errProfileNotFound
+	}
+	if len(matched) > 1 {
+		logSafef("several profiles map to one namespace, applying all of their policies: namespace=%q profiles=%q", namespace, strings.Join(names, ","))
+	}
+	return matched, nil
+}
+
+// getProfile fetches a single Profile with the admin client behind the
+// circuit breaker.
+func (s *server) getProfile(ctx context.Context, name string) (*unstructured.Unstructured, error) {
+	probe, err := s.adminBreaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	profile, err := s.adminDynamic.Resource(s.profileGVR).Get(ctx, name, metav1.GetOptions{})
+	s.adminBreaker.record(err, probe)
+	return profile, err
+}
+
+func (s *server) handleNamespacePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	namespace, ok := parseNamespacePolicyPath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	user, groups, err := s.identityFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	namespaces, err := s.resolveUserNamespaces(r.Context(), user, groups, s.legacyUserFromRequest(r))
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+	if !slices.Contains(namespaces, namespace) {
+		writeError(w, http.StatusForbidden, "requested namespace is not owned by current user")
+		return
+	}
+
+	policy, err := s.policyForNamespace(r.Context(), namespace)
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+	impClient, err := s.newImpersonatedClient(user, groups)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create Kubernetes client")
+		return
+	}
+
+	resp := s.policyResponse(namespace, policy)
+	resp.Quota = s.quotaResponse(r.Context(), impClient, namespace)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *server) policyResponse(namespace string, policy namespacePolicy) namespacePolicyResponse {
+	resp := namespacePolicyResponse{
+		Namespace:         namespace,
+		AllowedTypes:      make([]corev1.SecretType, 0, len(policy.allowedTypes)),
+		DefaultType:       s.defaultSecretType,
+		MaxPayloadBytes:   s.maxPayloadSize,
+		RequiredKeys:      make(map[corev1.SecretType][]string),
+		RequiredLabels:    s.requiredLabels,
+		AllowedRegistries: s.allowedRegistries,
+		AllowedKeys:       make(map[corev1.SecretType][]string),
+	}
+	for secretType := range policy.allowedTypes {
+		resp.AllowedTypes = append(resp.AllowedTypes, secretType)
+		if keys := typeRequiredKeys[secretType]; len(keys) > 0 {
+			resp.RequiredKeys[secretType] = keys
+		}
+		if keys := typeAllowedKeys[secretType]; s.strictTypeKeys && len(keys) > 0 {
+			resp.AllowedKeys[secretType] = keys
+		}
+	}
+	slices.Sort(resp.AllowedTypes)
+	return resp
+}
+
+// parseNamespacePolicyPath extracts {ns} from /api/namespaces/{ns}/policy.
+func parseNamespacePolicyPath(path string) (string, bool) {
+	raw := strings.TrimSuffix(strings.TrimPrefix(path, namespacesPathPrefix), "/")
+	namespace, rest, found := strings.Cut(raw, "/")
+	if !found || rest != "policy" {
+		return "", false
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", false
+	}
+	return namespace, true
+}
+
+func intersectAllowedTypes(global map[corev1.SecretType]struct{}, raw string) map[corev1.SecretType]struct{} {
+	out := make(map[corev1.SecretType]struct{})
+	for _, part := range strings.Split(raw, ",") {
+		secretType := corev1.SecretType(strings.TrimSpace(part))
+		if _, ok := global[secretType]; ok {
+			out[secretType] = struct{}{}
+		}
+	}
+	return out
+}
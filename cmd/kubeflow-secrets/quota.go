@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const errorCodeQuotaExceeded = "quota_exceeded"
+
+// secretQuotaResources are the ResourceQuota keys that cap the number of
+// secrets in a namespace.
+var secretQuotaResources = []corev1.ResourceName{
+	corev1.ResourceSecrets,
+	"count/secrets",
+}
+
+// secretQuotaLimits lists the secret-count limits of the namespace's
+// ResourceQuotas, read with the caller's identity.
+func secretQuotaLimits(ctx context.Context, client kubernetes.Interface, namespace string) ([]secretQuotaLimit, error) {
+	quotas, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make([]secretQuotaLimit, 0)
+	for _, quota := range quotas.Items {
+		for _, resource := range secretQuotaResources {
+			hard, ok := quota.Status.Hard[resource]
+			if !ok {
+				continue
+			}
+			limits = append(limits, secretQuotaLimit{Quota: quota.Name, Resource: resource, Hard: hard, Used: quota.Status.Used[resource]})
+		}
+	}
+	return limits, nil
+}
+
+// checkSecretQuota reports, when QUOTA_CHECK_ENABLED is set, whether one more
+// secret fits under the namespace's ResourceQuotas. Callers that cannot list
+// them get no precheck and fall back to the apiserver's own admission error.
+func (s *server) checkSecretQuota(ctx context.Context, client kubernetes.Interface, namespace string) (string, bool) {
+	if !s.quotaCheckEnabled {
+		return "", true
+	}
+
+	limits, err := secretQuotaLimits(ctx, client, namespace)
+	if err != nil {
+		if !apierrors.IsForbidden(err) {
+			logSafef("quota check skipped: namespace=%q err=%v", namespace, err)
+		}
+		return "", true
+	}
+
+	for _, limit := range limits {
+		if limit.Used.Cmp(limit.Hard) >= 0 {
+			return fmt.Sprintf("namespace quota %q allows %s secrets and %s are in use; delete unused secrets or ask an administrator to raise the quota",
+				limit.Quota, limit.Hard.String(), limit.Used.String()), false
+		}
+	}
+	return "", true
+}
+
+// quotaResponse describes the namespace's secret quotas for the policy
+// endpoint. Limits stay empty when the caller cannot read ResourceQuotas.
+func (s *server) quotaResponse(ctx context.Context, client kubernetes.Interface, namespace string) namespaceQuotaResponse {
+	resp := namespaceQuotaResponse{PrecheckEnabled: s.quotaCheckEnabled, Limits: []secretQuotaLimit{}}
+	limits, err := secretQuotaLimits(ctx, client, namespace)
+	if err != nil {
+		if !apierrors.IsForbidden(err) {
+			logSafef("policy quota skipped: namespace=%q err=%v", namespace, err)
+		}
+		return resp
+	}
+	resp.Limits = limits
+	return resp
+}
+
+// isQuotaRejection tells the apiserver's quota admission denial apart from
+// an RBAC forbidden; both arrive as 403.
+func isQuotaRejection(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	defaultRecentWindow    = 24 * time.Hour
+	defaultRecentWindowMax = 7 * 24 * time.Hour
+)
+
+// handleRecentSecrets serves GET /api/secrets:recent?window=24h, the managed
+// secrets created or modified within the window across the caller's
+// namespaces, newest change first, for a recent-activity view. Change times
+// come from lastModifiedTime. Namespaces are listed sequentially as in
+// handleStats; windows beyond RECENT_WINDOW_MAX are capped to it.
+func (s *server) handleRecentSecrets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	window := defaultRecentWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "window must be a positive duration such as 30m or 24h")
+			return
+		}
+		window = parsed
+	}
+	window = min(window, s.recentWindowMax)
+
+	user, groups, err := s.identityFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	impClient, err := s.newImpersonatedClient(user, groups)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create Kubernetes client")
+		return
+	}
+	namespaces, err := s.resolveUserNamespaces(r.Context(), user, groups, s.legacyUserFromRequest(r))
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+
+	since := time.Now().Add(-window)
+	resp := recentSecretsResponse{
+		Window: window.String(),
+		Since:  since,
+		Items:  make([]recentSecretItem, 0),
+		Errors: []statsError{},
+	}
+	selector := fmt.Sprintf("%s,%s!=true", managedLabelSelector(), hiddenKey)
+	for _, namespace := range namespaces {
+		secrets, err := listSecretsBySelector(r.Context(), impClient, namespace, selector)
+		if err != nil {
+			_, msg := mapKubeError(err, "failed to list secrets")
+			logSafef("recent secrets failed: namespace=%q err=%v", namespace, err)
+			resp.Errors = append(resp.Errors, statsError{Namespace: namespace, Error: msg})
+			continue
+		}
+		for i := range secrets {
+			secret := &secrets[i]
+			modified := lastModifiedTime(secret)
+			if isHiddenSecret(secret) || modified.Before(since) {
+				continue
+			}
+			resp.Items = append(resp.Items, recentSecretItem{
+				Name:         secret.Name,
+				Namespace:    secret.Namespace,
+				Type:         secret.Type,
+				LastModified: modified,
+				Created:      !secret.CreationTimestamp.Time.Before(since),
+			})
+		}
+	}
+
+	sort.SliceStable(resp.Items, func(i, j int) bool {
+		return resp.Items[i].LastModified.After(resp.Items[j].LastModified)
+	})
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	recentNamespacesPerUser  = 5
+	recentNamespacesMaxUsers = 10000
+)
+
+// namespaceRecents remembers, per user, the namespaces their requests
+// resolved to, most recent first, when NAMESPACE_RECENT_TRACKING is on. It
+// is in memory and per pod: a restart or another replica only loses the
+// ranking hint, never access.
+type namespaceRecents struct {
+	mu    sync.Mutex
+	users map[string]*userRecents
+}
+
+type userRecents struct {
+	namespaces []string
+	touched    time.Time
+}
+
+func newNamespaceRecents() *namespaceRecents {
+	return &namespaceRecents{users: make(map[string]*userRecents)}
+}
+
+// touch moves namespace to the front of user's list. At the user cap, the
+// user seen least recently is forgotten to make room.
+func (n *namespaceRecents) touch(user, namespace string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	entry, ok := n.users[user]
+	if !ok {
+		if len(n.users) >= recentNamespacesMaxUsers {
+			n.evictOldest()
+		}
+		entry = &userRecents{}
+		n.users[user] = entry
+	}
+	entry.touched = time.Now()
+	entry.namespaces = slices.DeleteFunc(entry.namespaces, func(existing string) bool { return existing == namespace })
+	entry.namespaces = slices.Insert(entry.namespaces, 0, namespace)
+	if len(entry.namespaces) > recentNamespacesPerUser {
+		entry.namespaces = entry.namespaces[:recentNamespacesPerUser]
+	}
+}
+
+// evictOldest drops the least recently seen user. Callers hold n.mu.
+func (n *namespaceRecents) evictOldest() {
+	oldest := ""
+	var oldestTouched time.Time
+	for user, entry := range n.users {
+		if oldest == "" || entry.touched.Before(oldestTouched) {
+			oldest, oldestTouched = user, entry.touched
+		}
+	}
+	delete(n.users, oldest)
+}
+
+func (n *namespaceRecents) recent(user string) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	entry, ok := n.users[user]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(entry.namespaces)
+}
+
+// rankProfileNamespaces keeps primary Profiles first, then puts recently used
+// namespaces ahead of the rest, most recent first. Ties keep the existing
+// alphabetical order.
+func rankProfileNamespaces(profiles []profileNamespace, recent []string) {
+	rank := func(profile profileNamespace) int {
+		if profile.Primary {
+			return -1
+		}
+		if i := slices.Index(recent, profile.Namespace); i >= 0 {
+			return i
+		}
+		return len(recent)
+	}
+	sort.SliceStable(profiles, func(i, j int) bool { return rank(profiles[i]) < rank(profiles[j]) })
+}
+
+var errInvalidNamespaceLimit = errors.New("limit must be a positive integer")
+
+// filterProfileNamespaces applies the namespace picker's ?q= and ?limit=.
+// q matches a prefix of the namespace or display name, ignoring case. It
+// returns the kept profiles and how many matched before the limit.
+func filterProfileNamespaces(profiles []profileNamespace, q, rawLimit string) ([]profileNamespace, int, error) {
+	limit := 0
+	if rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			return nil, 0, errInvalidNamespaceLimit
+		}
+		limit = parsed
+	}
+
+	q = strings.ToLower(q)
+	matched := make([]profileNamespace, 0, len(profiles))
+	for _, profile := range profiles {
+		if q == "" || strings.HasPrefix(profile.Namespace, q) || strings.HasPrefix(strings.ToLower(profile.DisplayName), q) {
+			matched = append(matched, profile)
+		}
+	}
+	total := len(matched)
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	dockerHubIndexHost    = "index.docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+	responseBodyDrainSize = 4 << 10
+	maxRegistryRedirects  = 5
+)
+
+var (
+	errRegistryAuthFailed     = errors.New("registry rejected credentials")
+	errRegistryAddressBlocked = errors.New("registry address is not publicly routable")
+)
+
+func (s *server) handleSecretTestRegistry(w http.ResponseWriter, r *http.Request, impClient kubernetes.Interface, userNamespace, secretName string) {
+	if !s.registryTestEnabled {
+		writeError(w, http.StatusNotImplemented, "registry test is disabled")
+		return
+	}
+
+	secret, err := s.getManagedSecret(r.Context(), impClient, userNamespace, secretName)
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to get secret")
+		writeError(w, status, msg)
+		return
+	}
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("secret type %q does not hold registry credentials", secret.Type))
+		return
+	}
+
+	auths, err := parseDockerConfigAuths(secret.Data[corev1.DockerConfigJsonKey])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	registries := make([]string, 0, len(auths))
+	for registry := range auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+
+	results := make([]registryTestResult, 0, len(registries))
+	for _, registry := range registries {
+		results = append(results, s.testRegistryCredentials(r.Context(), registry, auths[registry]))
+	}
+
+	logSafef("registry test: namespace=%q name=%q registries=%d", userNamespace, secretName, len(results))
+	writeJSON(w, http.StatusOK, registryTestResponse{
+		Name:      secretName,
+		Namespace: userNamespace,
+		Results:   results,
+	})
+}
+
+func parseDockerConfigAuths(raw []byte) (map[string]dockerConfigEntry, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("secret has no %q key", corev1.DockerConfigJsonKey)
+	}
+
+	var cfg dockerConfigJSON
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("%q is not valid JSON", corev1.DockerConfigJsonKey)
+	}
+	if len(cfg.Auths) == 0 {
+		return nil, fmt.Errorf("%q has no auths entries", corev1.DockerConfigJsonKey)
+	}
+	return cfg.Auths, nil
+}
+
+func (s *server) testRegistryCredentials(ctx context.Context, registry string, entry dockerConfigEntry) registryTestResult {
+	result := registryTestResult{Registry: registry}
+
+	username, password, err := entry.credentials()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// Registries come from user data, so only those ALLOWED_REGISTRIES
+	// covers are called; secrets written before the list changed may name
+	// others.
+	host, err := registryHostname(registry)
+	if err != nil || !registryAllowed(host, s.allowedRegistries) {
+		result.Error = "registry is not in ALLOWED_REGISTRIES; not tested"
+		return result
+	}
+	baseURL, err := registryBaseURL(registry)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	status, err := s.pingRegistry(ctx, baseURL+"/v2/", username, password)
+	result.StatusCode = status
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+func (e dockerConfigEntry) credentials() (string, string, error) {
+	if e.Username != "" || e.Password != "" {
+		return e.Username, e.Password, nil
+	}
+	if e.Auth == "" {
+		return "", "", errors.New("entry has no credentials")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+	if err != nil {
+		return "", "", errors.New("auth field is not valid base64")
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", errors.New("auth field is not in user:password form")
+	}
+	return username, password, nil
+}
+
+// registryHostname reduces a docker config auths key, which may be a bare
+// host, host:port or a URL, to its lowercase host[:port]. Docker Hub's
+// aliases all become docker.io.
+func registryHostname(registry string) (string, error) {
+	raw := strings.TrimSpace(registry)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "", errors.New("invalid registry address")
+	}
+
+	host := strings.ToLower(parsed.Host)
+	if host == dockerHubIndexHost || host == dockerHubRegistryHost {
+		host = "docker.io"
+	}
+	return host, nil
+}
+
+// registryAllowed matches a hostname against ALLOWED_REGISTRIES entries,
+// where "*.example.com" covers any subdomain of example.com.
+func registryAllowed(host string, allowed []string) bool {
+	for _, entry := range allowed {
+		if suffix, ok := strings.CutPrefix(entry, "*"); ok && strings.HasSuffix(host, suffix) {
+			return true
+		}
+		if host == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// disallowedRegistries returns the sorted auths entries of a docker config
+// that ALLOWED_REGISTRIES does not cover.
+func disallowedRegistries(raw []byte, allowed []string) ([]string, error) {
+	auths, err := parseDockerConfigAuths(raw)
+	if err != nil {
+		return nil, err
+	}
+	denied := make([]string, 0)
+	for registry := range auths {
+		host, err := registryHostname(registry)
+		if err != nil || !registryAllowed(host, allowed) {
+			denied = append(denied, registry)
+		}
+	}
+	sort.Strings(denied)
+	return denied, nil
+}
+
+func registryBaseURL(registry string) (string, error) {
+	raw := strings.TrimSpace(registry)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "", errors.New("invalid registry address")
+	}
+
+	host := parsed.Host
+	if host == dockerHubIndexHost || host == "docker.io" {
+		host = dockerHubRegistryHost
+	}
+	return "https://" + host, nil
+}
+
+// pingRegistry hits the registry's /v2/ endpoint with basic credentials and,
+// when the registry answers with a bearer challenge, exchanges them at the
+// advertised token realm. No image content is pulled.
+func (s *server) pingRegistry(ctx context.Context, endpoint, username, password string) (int, error) {
+	resp, err := s.registryGet(ctx, endpoint, username, password)
+	if err != nil {
+		return 0, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	drainAndCloseBody(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return resp.StatusCode, nil
+	case resp.StatusCode != http.StatusUnauthorized:
+		return resp.StatusCode, fmt.Errorf("unexpected registry status %d", resp.StatusCode)
+	}
+
+	scheme, params := parseAuthChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") {
+		return resp.StatusCode, errRegistryAuthFailed
+	}
+
+	tokenURL, err := registryTokenURL(params)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	tokenResp, err := s.registryGet(ctx, tokenURL, username, password)
+	if err != nil {
+		return 0, err
+	}
+	drainAndCloseBody(tokenResp)
+	if tokenResp.StatusCode != http.StatusOK {
+		return tokenResp.StatusCode, errRegistryAuthFailed
+	}
+	return tokenResp.StatusCode, nil
+}
+
+// nonPublicPrefixes are ranges netip has no predicate for: carrier-grade
+// NAT, which some clouds use for internal services, and the benchmarking
+// range.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// publicAddr reports whether a registry call may connect to ip. Loopback,
+// private, link-local (which includes cloud metadata endpoints such as
+// 169.254.169.254) and unspecified addresses are refused.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newRegistryClient builds the client for registry tests. The address check
+// runs on the resolved IP at dial time, for the registry, its token realm
+// and any redirect alike, so a hostname that resolves or rebinds to an
+// internal address cannot turn the test into a probe of cluster services.
+// No proxy is used, since the check would then only see the proxy.
+func newRegistryClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddr(addrPort.Addr()) {
+				return errRegistryAddressBlocked
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" || len(via) >= maxRegistryRedirects {
+				return errors.New("registry redirect not followed")
+			}
+			return nil
+		},
+	}
+}
+
+func (s *server) registryGet(ctx context.Context, endpoint, username, password string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, errors.New("invalid registry request")
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := s.registryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registry unreachable: %w", err)
+	}
+	return resp, nil
+}
+
+func drainAndCloseBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, responseBodyDrainSize))
+	if err := resp.Body.Close(); err != nil {
+		logSafef("failed to close response body: %v", err)
+	}
+}
+
+func registryTokenURL(params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme != "https" || realm.Host == "" {
+		return "", errors.New("registry advertised an invalid token realm")
+	}
+	if ip, err := netip.ParseAddr(realm.Hostname()); (err == nil && !publicAddr(ip)) || strings.EqualFold(realm.Hostname(), "localhost") {
+		return "", errors.New("registry advertised a token realm on a non-public address")
+	}
+
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	realm.RawQuery = query.Encode()
+	return realm.String(), nil
+}
+
+// parseAuthChallenge splits a WWW-Authenticate header such as
+// `Bearer realm="https://auth.example.com/token",service="registry"` into its
+// scheme and parameters. Quoted values may contain commas.
+func parseAuthChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := make(map[string]string)
+
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(after, "\"") {
+			end := strings.Index(after[1:], "\"")
+			if end < 0 {
+				break
+			}
+			value = after[1 : end+1]
+			rest = after[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(after, ",")
+		}
+		params[key] = strings.TrimSpace(value)
+	}
+
+	return scheme, params
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Values of resolveProfile.Match.
+const (
+	resolveMatchOwner       = "owner"
+	resolveMatchLegacyOwner = "legacyOwner"
+	resolveMatchAccess      = "access"
+)
+
+// handleAdminResolve serves GET /api/admin/resolve?user=&groups=&legacyUser=,
+// which runs namespace resolution for someone else and explains it: the
+// identity candidates, every Profile's owner with how (or whether) it
+// matched, and the final namespaces or error. It answers the questions the
+// "profile match failed" log line does, without access to the logs.
+func (s *server) handleAdminResolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.isAdminRequest(r) {
+		writeError(w, http.StatusForbidden, "admin group required")
+		return
+	}
+
+	query := r.URL.Query()
+	user := strings.TrimSpace(query.Get("user"))
+	if user == "" {
+		writeError(w, http.StatusBadRequest, "user is required")
+		return
+	}
+	groups := normalizeGroups([]string{query.Get("groups")})
+	legacyUser := strings.TrimSpace(query.Get("legacyUser"))
+
+	admin, _, _ := s.identityFromRequest(r)
+	logSafef("admin resolve: admin=%q user=%q legacy_user=%q", sanitizeForLog(admin), sanitizeForLog(user), sanitizeForLog(legacyUser))
+
+	resp := resolveResponse{
+		User:             user,
+		Groups:           groups,
+		LegacyUser:       legacyUser,
+		Candidates:       s.identityCandidates(user),
+		LegacyCandidates: s.identityCandidates(legacyUser),
+		Namespaces:       make([]profileNamespace, 0),
+	}
+
+	namespaces, err := s.resolveUserProfiles(r.Context(), user, groups, legacyUser)
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		resp.Status, resp.Error = status, msg
+	} else {
+		resp.Status = http.StatusOK
+		resp.Namespaces = namespaces
+	}
+
+	profiles, err := s.listProfiles(r.Context(), metav1.ListOptions{})
+	if err != nil {
+		status, msg := mapKubeError(err, "failed to list profiles")
+		writeError(w, status, msg)
+		return
+	}
+	resolved := profileNamespaceNames(resp.Namespaces)
+	resp.Profiles = make([]resolveProfile, 0, len(profiles.Items))
+	for i := range profiles.Items {
+		resp.Profiles = append(resp.Profiles, s.explainProfile(&profiles.Items[i], resp.Candidates, resp.LegacyCandidates, resolved))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// explainProfile mirrors the matching in resolveProfileNamespaces for one
+// Profile. Namespaces reached through RBAC rather than ownership show up as
+// "access" when they made it into the result.
+func (s *server) explainProfile(profile *unstructured.Unstructured, candidates, legacyCandidates, resolved []string) resolveProfile {
+	item := resolveProfile{
+		Name:      profile.GetName(),
+		Namespace: s.newProfileNamespace(profile).Namespace,
+	}
+	owner, found, err := unstructured.NestedString(profile.Object, s.profileOwnerPath...)
+	if err == nil && found {
+		item.Owner = owner
+		item.OwnerCandidates = s.identityCandidates(owner)
+	}
+
+	switch {
+	case identitiesMatch(candidates, item.OwnerCandidates):
+		item.Match = resolveMatchOwner
+	case identitiesMatch(legacyCandidates, item.OwnerCandidates):
+		item.Match = resolveMatchLegacyOwner
+	case item.Namespace != "" && slices.Contains(resolved, item.Namespace):
+		item.Match = resolveMatchAccess
+	}
+	if item.Namespace != "" && item.Match != "" && !slices.Contains(resolved, item.Namespace) {
+		item.Filtered = true
+	}
+	return item
+}
@@ -1,16 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
+	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 )
@@ -26,56 +34,136 @@ func (s *server) getManagedSecret(ctx context.Context, client kubernetes.Interfa
 	return secret, nil
 }
 
-func (s *server) validateAndBuildSecret(req secretUpsertRequest) (*corev1.Secret, error) {
+// checkNameCase explains a create whose name differs from a valid name only
+// by case. DNS-1123 rejects uppercase anyway, but users expect "MySecret" to
+// conflict with an existing "mysecret", so say so and suggest the canonical
+// name instead of returning the bare validation error. It returns ok when the
+// name needs no such explanation.
+func (s *server) checkNameCase(ctx context.Context, client kubernetes.Interface, namespace, name string) (int, string, bool) {
+	canonical := strings.ToLower(name)
+	if canonical == name || len(validation.IsDNS1123Subdomain(canonical)) > 0 {
+		return 0, "", true
+	}
+
+	_, err := s.getManagedSecret(ctx, client, namespace, canonical)
+	if err == nil {
+		return http.StatusConflict, fmt.Sprintf("secret %q already exists; names are lowercase, so %q would be the same secret", canonical, name), false
+	}
+	return http.StatusBadRequest, fmt.Sprintf("secret names must be lowercase; use %q", canonical), false
+}
+
+// validateAndBuildSecret checks an upsert request and builds the secret to
+// write. The notes describe changes it made to the input, such as trimmed
+// values, for the response's warnings.
+func (s *server) validateAndBuildSecret(req secretUpsertRequest, policy namespacePolicy) (*corev1.Secret, []string, error) {
 	namespace := strings.TrimSpace(req.Namespace)
 	name := strings.TrimSpace(req.Name)
 
 	if namespace == "" {
-		return nil, errors.New("namespace is required")
+		return nil, nil, errors.New("namespace is required")
 	}
 	if name == "" {
-		return nil, errors.New("name is required")
+		return nil, nil, errors.New("name is required")
 	}
 	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
-		return nil, fmt.Errorf("invalid secret name: %s", strings.Join(errs, ", "))
+		return nil, nil, fmt.Errorf("invalid secret name: %s", strings.Join(errs, ", "))
+	}
+	if len(name) > s.maxNameLength {
+		return nil, nil, fmt.Errorf("secret name is %d characters; this server allows at most %d", len(name), s.maxNameLength)
+	}
+
+	req, err := applyTypeTransformers(req)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	secretType := req.Type
 	if secretType == "" {
-		secretType = corev1.SecretTypeOpaque
+		secretType = s.defaultSecretType
 	}
 	if _, blocked := s.blockedTypes[secretType]; blocked {
-		return nil, fmt.Errorf("secret type %q is not allowed", secretType)
+		return nil, nil, fmt.Errorf("secret type %q is not allowed", secretType)
 	}
 	if _, ok := s.allowedTypes[secretType]; !ok {
-		return nil, fmt.Errorf("secret type %q is not in allowed list", secretType)
+		return nil, nil, fmt.Errorf("secret type %q is not in allowed list", secretType)
+	}
+	if _, ok := policy.allowedTypes[secretType]; !ok {
+		return nil, nil, fmt.Errorf("secret type %q is not allowed in namespace %q", secretType, namespace)
 	}
 
 	if len(req.Data) == 0 && len(req.StringData) == 0 {
-		return nil, errors.New("either data or stringData must be provided")
+		return nil, nil, errors.New("either data or stringData must be provided")
+	}
+
+	for key, value := range req.StringData {
+		if !utf8.ValidString(value) {
+			return nil, nil, invalidUTF8StringDataError(key)
+		}
+	}
+
+	notes := make([]string, 0)
+	if s.trimStringValues {
+		var trimmed []string
+		req.StringData, trimmed = trimStringValues(req.StringData)
+		if len(trimmed) > 0 {
+			notes = append(notes, fmt.Sprintf("trimmed leading/trailing whitespace from stringData values: %s", strings.Join(trimmed, ", ")))
+		}
 	}
 
 	decodedData := make(map[string][]byte, len(req.Data))
 	for key, value := range req.Data {
 		if strings.TrimSpace(key) == "" {
-			return nil, errors.New("data contains an empty key")
+			return nil, nil, errors.New("data contains an empty key")
 		}
 		decoded, err := base64.StdEncoding.DecodeString(value)
 		if err != nil {
-			return nil, fmt.Errorf("data[%q] is not valid base64", key)
+			return nil, nil, fmt.Errorf("data[%q] is not valid base64", key)
 		}
 		decodedData[key] = decoded
 	}
 
-	if secretType == corev1.SecretTypeDockerConfigJson {
-		if _, ok := decodedData[corev1.DockerConfigJsonKey]; !ok {
-			if _, okString := req.StringData[corev1.DockerConfigJsonKey]; !okString {
-				return nil, fmt.Errorf("dockerconfigjson secret requires %q key", corev1.DockerConfigJsonKey)
+	if s.rejectNullBytes {
+		if key, found := nullByteKey(decodedData, req.StringData); found {
+			return nil, nil, fmt.Errorf("value of key %q contains a null byte", key)
+		}
+	}
+
+	if err := s.checkMetadataCounts(req); err != nil {
+		return nil, nil, err
+	}
+	if missing := s.missingRequiredLabels(req.Labels); len(missing) > 0 {
+		return nil, nil, fmt.Errorf("secret is missing required labels: %s", strings.Join(missing, ", "))
+	}
+
+	for _, key := range typeRequiredKeys[secretType] {
+		if _, ok := decodedData[key]; !ok {
+			if _, okString := req.StringData[key]; !okString {
+				return nil, nil, fmt.Errorf("%s secret requires %q key", secretType, key)
 			}
 		}
 	}
 
-	return &corev1.Secret{
+	if len(s.allowedRegistries) > 0 && secretType == corev1.SecretTypeDockerConfigJson {
+		raw, ok := req.StringData[corev1.DockerConfigJsonKey]
+		if !ok {
+			raw = string(decodedData[corev1.DockerConfigJsonKey])
+		}
+		denied, err := disallowedRegistries([]byte(raw), s.allowedRegistries)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(denied) > 0 {
+			return nil, nil, fmt.Errorf("registries not in the allowed list: %s", strings.Join(denied, ", "))
+		}
+	}
+
+	if s.strictTypeKeys {
+		if extra := extraTypeKeys(secretType, decodedData, req.StringData); len(extra) > 0 {
+			return nil, nil, fmt.Errorf("%s secret does not allow keys: %s", secretType, strings.Join(extra, ", "))
+		}
+	}
+
+	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
 			Namespace:   namespace,
@@ -85,7 +173,36 @@ func (s *server) validateAndBuildSecret(req secretUpsertRequest) (*corev1.Secret
 		Type:       secretType,
 		Data:       decodedData,
 		StringData: copyStringMap(req.StringData),
-	}, nil
+	}
+	if validate, ok := typeValidators[secretType]; ok {
+		if err := validate(secretValues(secret)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return secret, notes, nil
+}
+
+// trimStringValues implements TRIM_STRING_VALUES: a copy of stringData with
+// surrounding whitespace removed, and the sorted keys that changed. Pasted
+// tokens often carry a trailing newline that consumers then send verbatim.
+func trimStringValues(stringData map[string]string) (map[string]string, []string) {
+	if len(stringData) == 0 {
+		return stringData, nil
+	}
+	out := make(map[string]string, len(stringData))
+	trimmed := make([]string, 0)
+	for key, value := range stringData {
+		out[key] = strings.TrimSpace(value)
+		if out[key] != value {
+			trimmed = append(trimmed, key)
+		}
+	}
+	sort.Strings(trimmed)
+	return out, trimmed
+}
+
+func invalidUTF8StringDataError(key string) error {
+	return fmt.Errorf("stringData[%q] is not valid UTF-8; send binary values base64-encoded in data", key)
 }
 
 func secretToDetail(secret *corev1.Secret) secretDetailResponse {
@@ -99,56 +216,298 @@ func secretToDetail(secret *corev1.Secret) secretDetailResponse {
 		}
 	}
 
-	return secretDetailResponse{
+	detail := secretDetailResponse{
+		UID:               secret.UID,
 		Name:              secret.Name,
 		Namespace:         secret.Namespace,
 		Type:              secret.Type,
+		ResourceVersion:   secret.ResourceVersion,
 		CreationTimestamp: secret.CreationTimestamp.Time,
+		Age:               secretAge(secret.CreationTimestamp.Time),
 		Labels:            copyStringMapOrEmpty(secret.Labels),
 		Annotations:       copyStringMapOrEmpty(secret.Annotations),
 		Data:              data,
 		StringData:        stringData,
 	}
+	detail.ToolVersion = secret.Annotations[versionAnnotation]
+	if isImmutableSecret(secret) {
+		detail.Immutable = true
+		detail.ImmutableNote = immutableSecretNote
+	}
+	return detail
+}
+
+func isImmutableSecret(secret *corev1.Secret) bool {
+	return secret.Immutable != nil && *secret.Immutable
 }
 
-func parseSecretPath(path string) (string, string, error) {
+// secretDataChanged reports whether the data an update would store differs
+// from what the existing secret holds. stringData wins over data for the same
+// key, as on the apiserver.
+func secretDataChanged(existing, updated *corev1.Secret) bool {
+	effective := make(map[string][]byte, len(updated.Data)+len(updated.StringData))
+	for key, value := range updated.Data {
+		effective[key] = value
+	}
+	for key, value := range updated.StringData {
+		effective[key] = []byte(value)
+	}
+	return !maps.EqualFunc(existing.Data, effective, bytes.Equal)
+}
+
+// lastAppliedAnnotation is written by kubectl apply and can hold a previous
+// copy of the data, so it never goes into re-appliable manifests.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// redactedValue replaces data values in redacted manifests. It marshals to
+// valid base64, so the YAML still parses and decodes, and spells out what
+// happened if someone applies it by mistake.
+var redactedValue = []byte("REDACTED")
+
+// cleanManifest renders a secret without server-populated fields
+// (resourceVersion, uid, creationTimestamp, managedFields, ...) so it can be
+// kubectl-applied as is. Data values become redactedValue unless reveal is
+// set; the keys stay so the structure is visible.
+func cleanManifest(secret *corev1.Secret, reveal bool) cleanSecretManifest {
+	annotations := copyStringMap(secret.Annotations)
+	delete(annotations, lastAppliedAnnotation)
+
+	data := make(map[string][]byte, len(secret.Data))
+	for key, value := range secret.Data {
+		if reveal {
+			data[key] = value
+		} else {
+			data[key] = redactedValue
+		}
+	}
+
+	return cleanSecretManifest{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: cleanManifestMetadata{
+			Name:        secret.Name,
+			Namespace:   secret.Namespace,
+			Labels:      ensureManagedLabels(secret.Labels),
+			Annotations: annotations,
+		},
+		Type:      secret.Type,
+		Immutable: secret.Immutable,
+		Data:      data,
+	}
+}
+
+// nullByteKey returns the first key, in sorted order, whose value contains
+// a NUL byte. Kubernetes stores these fine but many consumers (env vars, C
+// strings, config parsers) truncate or choke on them.
+func nullByteKey(data map[string][]byte, stringData map[string]string) (string, bool) {
+	keys := make([]string, 0, len(data)+len(stringData))
+	for key, value := range data {
+		if bytes.IndexByte(value, 0) >= 0 {
+			keys = append(keys, key)
+		}
+	}
+	for key, value := range stringData {
+		if strings.IndexByte(value, 0) >= 0 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return "", false
+	}
+	sort.Strings(keys)
+	return keys[0], true
+}
+
+// checkMetadataCounts enforces MAX_LABELS/MAX_ANNOTATIONS. Keys the server
+// sets itself (managed-by, hidden, created-by, provenance, version) are not
+// counted, so clients get exactly the configured budget.
+func (s *server) checkMetadataCounts(req secretUpsertRequest) error {
+	if labels := countClientKeys(req.Labels, managedByLabelKey, legacyManagedByLabelKey, hiddenKey); s.maxLabels > 0 && labels > s.maxLabels {
+		return fmt.Errorf("secret has %d labels; this server allows at most %d", labels, s.maxLabels)
+	}
+	if annotations := countClientKeys(req.Annotations, append(serverOwnedAnnotations, versionAnnotation, hiddenKey)...); s.maxAnnotations > 0 && annotations > s.maxAnnotations {
+		return fmt.Errorf("secret has %d annotations; this server allows at most %d", annotations, s.maxAnnotations)
+	}
+	return nil
+}
+
+func countClientKeys(values map[string]string, serverKeys ...string) int64 {
+	count := int64(len(values))
+	for _, key := range serverKeys {
+		if _, ok := values[key]; ok {
+			count--
+		}
+	}
+	return count
+}
+
+// missingRequiredLabels returns the REQUIRED_LABELS keys that labels lacks
+// or leaves empty.
+func (s *server) missingRequiredLabels(labels map[string]string) []string {
+	missing := make([]string, 0)
+	for _, key := range s.requiredLabels {
+		if strings.TrimSpace(labels[key]) == "" {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// redactDetail blanks data values in place, keeping the keys so the
+// structure is still visible.
+func redactDetail(detail *secretDetailResponse) {
+	for key := range detail.Data {
+		detail.Data[key] = ""
+	}
+	for key := range detail.StringData {
+		detail.StringData[key] = ""
+	}
+}
+
+// contentHashHeader carries contentHash of the data a detail response
+// returns, so clients can verify the payload and tell whether a re-fetched
+// secret changed without comparing values themselves.
+const contentHashHeader = "X-Content-Hash"
+
+// contentHash is "sha256:" plus the hex SHA-256 of one "key=base64value\n"
+// line per key, sorted by key: the detail response's data, canonicalized.
+func contentHash(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, data[key])
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// projectDetailKeys narrows the detail payload to the requested data keys and
+// returns the requested keys that the secret does not hold.
+func projectDetailKeys(detail *secretDetailResponse, keys []string) []string {
+	wanted := make(map[string]struct{}, len(keys))
+	missing := make([]string, 0)
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if _, ok := detail.Data[key]; !ok {
+			missing = append(missing, key)
+			continue
+		}
+		wanted[key] = struct{}{}
+	}
+	if len(missing) > 0 {
+		return missing
+	}
+
+	for key := range detail.Data {
+		if _, ok := wanted[key]; !ok {
+			delete(detail.Data, key)
+			delete(detail.StringData, key)
+		}
+	}
+	return nil
+}
+
+// lastModifiedTime derives when a secret was last written from the newest
+// managedFields entry, falling back to the creation time when the apiserver
+// did not record any.
+func lastModifiedTime(secret *corev1.Secret) time.Time {
+	modified := secret.CreationTimestamp.Time
+	for _, entry := range secret.ManagedFields {
+		if entry.Time != nil && entry.Time.After(modified) {
+			modified = entry.Time.Time
+		}
+	}
+	return modified
+}
+
+// secretAge renders the time since creation the way kubectl's AGE column
+// does (e.g. 45s, 5h, 3d), so every client shows the same value.
+func secretAge(created time.Time) string {
+	if created.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(created))
+}
+
+func parseSecretPath(path string) (string, string, string, error) {
 	if !strings.HasPrefix(path, secretsPathPrefix) {
-		return "", "", errors.New("invalid path")
+		return "", "", "", errors.New("invalid path")
 	}
 
-	raw := strings.TrimPrefix(path, secretsPathPrefix)
+	// A single trailing slash is tolerated so /api/secrets/{name}/ and
+	// /api/secrets/{name}/yaml/ resolve like their slash-less forms.
+	raw := strings.TrimSuffix(strings.TrimPrefix(path, secretsPathPrefix), "/")
 	if raw == "" {
-		return "", "", errors.New("invalid secret name")
+		return "", "", "", errors.New("invalid secret name")
 	}
 
 	parts := strings.Split(raw, "/")
 	switch len(parts) {
 	case 1, secretPathWithSubresourceParts:
+	case secretKeyDownloadPathParts:
+		return parseSecretKeyDownloadPath(parts)
 	default:
-		return "", "", errors.New("invalid path")
+		return "", "", "", errors.New("invalid path")
 	}
 
-	name, err := url.PathUnescape(parts[0])
+	unescaped, err := url.PathUnescape(parts[0])
 	if err != nil {
-		return "", "", errors.New("invalid secret name")
+		return "", "", "", errors.New("invalid secret name")
 	}
+	name, action, hasAction := strings.Cut(unescaped, ":")
 	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
-		return "", "", errors.New("invalid secret name")
+		return "", "", "", errors.New("invalid secret name")
+	}
+
+	if hasAction {
+		if len(parts) != 1 {
+			return "", "", "", errors.New("invalid path")
+		}
+		switch action {
+		case secretActionTestRegistry, secretActionHide, secretActionUnhide, secretActionAvailable:
+		default:
+			return "", "", "", errors.New("invalid path")
+		}
+		return name, action, "", nil
 	}
 
 	subresource := ""
 	if len(parts) == secretPathWithSubresourceParts {
 		subresource = strings.TrimSpace(parts[1])
 		if subresource == "" {
-			return "", "", errors.New("invalid path")
+			return "", "", "", errors.New("invalid path")
 		}
 		switch subresource {
-		case secretSubresourceEvents, secretSubresourceYAML:
+		case secretSubresourceEvents, secretSubresourceYAML, secretSubresourceDotenv:
 		default:
-			return "", "", errors.New("invalid path")
+			return "", "", "", errors.New("invalid path")
 		}
 	}
-	return name, subresource, nil
+	return name, subresource, "", nil
+}
+
+// parseSecretKeyDownloadPath handles {name}/keys/{key}/download, returning
+// the key as the third value.
+func parseSecretKeyDownloadPath(parts []string) (string, string, string, error) {
+	if parts[1] != secretKeysSegment || parts[3] != secretSubresourceDownload {
+		return "", "", "", errors.New("invalid path")
+	}
+	name, err := url.PathUnescape(parts[0])
+	if err != nil || len(validation.IsDNS1123Subdomain(name)) > 0 {
+		return "", "", "", errors.New("invalid secret name")
+	}
+	key, err := url.PathUnescape(parts[2])
+	if err != nil || len(validation.IsConfigMapKey(key)) > 0 {
+		return "", "", "", errors.New("invalid secret key")
+	}
+	return name, secretSubresourceDownload, key, nil
 }
 
 func copyStringMap(in map[string]string) map[string]string {
@@ -169,12 +528,26 @@ func copyStringMapOrEmpty(in map[string]string) map[string]string {
 	return copyStringMap(in)
 }
 
+// managedByLabelKey is MANAGED_BY_LABEL_KEY, the label marking the secrets
+// this server manages. legacyManagedByLabelKey, when set, is the key being
+// migrated away from: while it is set, writes carry both keys, lists still
+// select on the legacy key (the only one every secret has) and either key
+// counts as managed. Once migrate-labels has stamped the new key everywhere,
+// unsetting it switches lists over. Both are set once by newServer.
+var (
+	managedByLabelKey       = defaultManagedByLabelKey
+	legacyManagedByLabelKey string
+)
+
 func ensureManagedLabels(in map[string]string) map[string]string {
 	labels := copyStringMap(in)
 	if labels == nil {
-		labels = make(map[string]string, 1)
+		labels = make(map[string]string, 2)
 	}
 	labels[managedByLabelKey] = managedByLabelValue
+	if legacyManagedByLabelKey != "" {
+		labels[legacyManagedByLabelKey] = managedByLabelValue
+	}
 	return labels
 }
 
@@ -182,9 +555,20 @@ func isManagedSecret(secret *corev1.Secret) bool {
 	if secret == nil || secret.Labels == nil {
 		return false
 	}
+	if legacyManagedByLabelKey != "" && secret.Labels[legacyManagedByLabelKey] == managedByLabelValue {
+		return true
+	}
 	return secret.Labels[managedByLabelKey] == managedByLabelValue
 }
 
+// managedSelectorKey is the label key lists select on.
+func managedSelectorKey() string {
+	if legacyManagedByLabelKey != "" {
+		return legacyManagedByLabelKey
+	}
+	return managedByLabelKey
+}
+
 func managedLabelSelector() string {
-	return fmt.Sprintf("%s=%s", managedByLabelKey, managedByLabelValue)
+	return fmt.Sprintf("%s=%s", managedSelectorKey(), managedByLabelValue)
 }
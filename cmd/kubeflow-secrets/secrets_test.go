@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestParseSecretPath(t *testing.T) {
+	tests := []struct {
+		path        string
+		name        string
+		subresource string
+		key         string
+		wantErr     bool
+	}{
+		{path: "/api/secrets/", wantErr: true},
+		{path: "/api/secrets/db", name: "db"},
+		{path: "/api/secrets/db/", name: "db"},
+		{path: "/api/secrets/db/yaml", name: "db", subresource: secretSubresourceYAML},
+		{path: "/api/secrets/db/yaml/", name: "db", subresource: secretSubresourceYAML},
+		{path: "/api/secrets/db/events", name: "db", subresource: secretSubresourceEvents},
+		{path: "/api/secrets/db//", wantErr: true},
+		{path: "/api/secrets/db/bogus", wantErr: true},
+		{path: "/api/secrets/db:hide", name: "db", subresource: secretActionHide},
+		{path: "/api/secrets/db:bogus", wantErr: true},
+		{path: "/api/secrets/db:hide/yaml", wantErr: true},
+		{path: "/api/secrets/Not_A_Name", wantErr: true},
+		{path: "/api/secrets/db/keys/password/download", name: "db", subresource: secretSubresourceDownload, key: "password"},
+		{path: "/api/secrets/db/keys/bad%2Fkey/download", wantErr: true},
+		{path: "/api/secrets/db/keys/password/view", wantErr: true},
+		{path: "/api/secrets/db/yaml/extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			name, subresource, key, err := parseSecretPath(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got name=%q subresource=%q key=%q, want an error", name, subresource, key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != tt.name || subresource != tt.subresource || key != tt.key {
+				t.Fatalf("got name=%q subresource=%q key=%q, want %q %q %q", name, subresource, key, tt.name, tt.subresource, tt.key)
+			}
+		})
+	}
+}
+
+func TestCleanManifestRedactsToValidBase64(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: testNamespace},
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"password": []byte("s3cr3t"),
+			"empty":    {},
+			"binary":   {0x00, 0xff, 0x10},
+		},
+	}
+
+	content, err := yaml.Marshal(cleanManifest(secret, false))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !bytes.Contains(content, []byte("UkVEQUNURUQ=")) {
+		t.Fatalf("manifest does not carry the base64 placeholder:\n%s", content)
+	}
+
+	var parsed corev1.Secret
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		t.Fatalf("redacted manifest does not parse as a Secret: %v\n%s", err, content)
+	}
+	if len(parsed.Data) != len(secret.Data) {
+		t.Fatalf("got keys %v, want the keys of %v", parsed.Data, secret.Data)
+	}
+	for key, value := range parsed.Data {
+		if !bytes.Equal(value, redactedValue) {
+			t.Errorf("data[%q] = %q, want %q", key, value, redactedValue)
+		}
+	}
+}
@@ -1,52 +1,144 @@
 package main
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"regexp"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
 
 const (
-	managedByLabelKey              = "managed-by"
+	defaultManagedByLabelKey       = "managed-by"
 	managedByLabelValue            = "kubeflow-secrets"
 	secretsPathPrefix              = "/api/secrets/"
+	namespacesPathPrefix           = "/api/namespaces/"
 	secretSubresourceEvents        = "events"
 	secretSubresourceYAML          = "yaml"
+	secretSubresourceDotenv        = "dotenv"
+	secretSubresourceDownload      = "download"
+	secretKeysSegment              = "keys"
+	secretActionTestRegistry       = "test-registry"
+	secretActionHide               = "hide"
+	secretActionUnhide             = "unhide"
+	secretActionAvailable          = "available"
+	hiddenKey                      = "kubeflow-secrets/hidden"
 	secretPathWithSubresourceParts = 2
+	secretKeyDownloadPathParts     = 4
 	maxPayloadBytes                = 1 << 20
+	resolvedNamespaceHeader        = "X-Resolved-Namespace"
+	cursorKeySize                  = 32
+
+	errorCodeImmutableSecret = "immutable_secret"
+	errorCodeImmutableType   = "immutable_type"
+	immutableSecretNote      = "data of an immutable secret cannot change; delete and recreate it instead"
 )
 
 var (
-	errProfileNotFound  = errors.New("no profile namespace found for user")
-	errSecretNotManaged = errors.New("secret is not managed by kubeflow-secrets")
+	errProfileNotFound    = errors.New("no profile namespace found for user")
+	errSecretNotManaged   = errors.New("secret is not managed by kubeflow-secrets")
+	errNamespacesFiltered = errors.New("all profile namespaces are excluded by server configuration")
 )
 
 type server struct {
-	baseConfig     *rest.Config
-	adminDynamic   dynamic.Interface
-	userHeader     string
-	groupsHeader   string
-	profileGVR     schema.GroupVersionResource
-	allowedTypes   map[corev1.SecretType]struct{}
-	blockedTypes   map[corev1.SecretType]struct{}
-	maxPayloadSize int64
+	baseConfig           *rest.Config
+	adminDynamic         dynamic.Interface
+	adminBreaker         *circuitBreaker
+	readyzLog            *logThrottle
+	logRequest           func(entry requestLog)
+	userHeader           string
+	groupsHeader         string
+	legacyUserHeader     string
+	profileGVR           schema.GroupVersionResource
+	profileOwnerPath     []string
+	profileOwnerLabel    string
+	profileNamespacePath []string
+	identityNormalize    *regexp.Regexp
+
+	tracer  *tracer
+	metrics *metrics
+
+	namespaceAllowlist   map[string]struct{}
+	namespaceDenylist    map[string]struct{}
+	trustedProxies       []netip.Prefix
+	allowedTypes         map[corev1.SecretType]struct{}
+	blockedTypes         map[corev1.SecretType]struct{}
+	defaultSecretType    corev1.SecretType
+	maxPayloadSize       int64
+	maxNameLength        int
+	maxLabels            int64
+	maxAnnotations       int64
+	requiredLabels       []string
+	allowedRegistries    []string
+	rejectNullBytes      bool
+	strictTypeKeys       bool
+	enforceTypeImmutable bool
+	trimStringValues     bool
+	warningChecks        []string
+	quotaCheckEnabled    bool
+	stampVersionEnabled  bool
+	provenanceEnabled    bool
+
+	importMaxBytes        int64
+	importMaxDocuments    int64
+	importDocumentTimeout time.Duration
+	operations            *operationStore
+	recentNamespaces      *namespaceRecents
+
+	sortedPaginationMax      int64
+	listDefaultLimit         int64
+	listMaxLimit             int64
+	cursorKey                []byte
+	cursorTTL                time.Duration
+	adminGroups              map[string]struct{}
+	legacyManagedValues      []string
+	writeLocks               *secretLocker
+	reads                    *readGroup
+	snapshots                *listSnapshots
+	writes                   *writeTracker
+	logKeyNames              bool
+	hiddenAnnotationPrefixes []string
+	eventsLimit              int64
+	eventsWindow             time.Duration
+	recentWindowMax          time.Duration
+
+	registryTestEnabled bool
+	registryClient      *http.Client
 }
 
-func newServer(cfg *rest.Config, userHeader, groupsHeader string) (*server, error) {
+func newServer(cfg *rest.Config, opts serverOptions) (*server, error) {
+	tracer := newTracer(opts.otlpEndpoint, opts.serviceName)
+	if tracer != nil {
+		cfg = rest.CopyConfig(cfg)
+		cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &tracingRoundTripper{tracer: tracer, next: rt}
+		})
+	}
+
 	adminDynamic, err := dynamic.NewForConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return &server{
-		baseConfig:   cfg,
-		adminDynamic: adminDynamic,
-		userHeader:   strings.ToLower(userHeader),
-		groupsHeader: strings.ToLower(groupsHeader),
+	srv := &server{
+		baseConfig:       cfg,
+		adminDynamic:     adminDynamic,
+		tracer:           tracer,
+		adminBreaker:     newCircuitBreaker(opts.breakerThreshold, opts.breakerCooldown),
+		readyzLog:        &logThrottle{interval: readyzLogInterval},
+		writes:           newWriteTracker(),
+		userHeader:       strings.ToLower(opts.userHeader),
+		groupsHeader:     strings.ToLower(opts.groupsHeader),
+		legacyUserHeader: strings.ToLower(opts.legacyUserHeader),
 		profileGVR: schema.GroupVersionResource{
 			Group:    "kubeflow.org",
 			Version:  "v1",
@@ -55,11 +147,134 @@ func newServer(cfg *rest.Config, userHeader, groupsHeader string) (*server, erro
 		allowedTypes: map[corev1.SecretType]struct{}{
 			corev1.SecretTypeOpaque:           {},
 			corev1.SecretTypeDockerConfigJson: {},
+			corev1.SecretTypeTLS:              {},
 		},
 		blockedTypes: map[corev1.SecretType]struct{}{
 			corev1.SecretTypeServiceAccountToken: {},
 			corev1.SecretTypeBootstrapToken:      {},
 		},
 		maxPayloadSize: maxPayloadBytes,
-	}, nil
+
+		sortedPaginationMax: opts.sortedPaginationMax,
+		adminGroups:         stringSet(opts.adminGroups),
+		legacyManagedValues: opts.legacyManagedValues,
+
+		registryTestEnabled: opts.registryTestEnabled,
+		registryClient:      newRegistryClient(opts.registryTestTimeout),
+	}
+
+	if _, ok := srv.allowedTypes[opts.defaultSecretType]; !ok {
+		return nil, fmt.Errorf("default secret type %q is not in allowed list", opts.defaultSecretType)
+	}
+	srv.defaultSecretType = opts.defaultSecretType
+	srv.logRequest, err = newRequestLogger(opts.logFormat)
+	if err != nil {
+		return nil, err
+	}
+	srv.cursorTTL = opts.cursorTTL
+	srv.cursorKey = []byte(opts.cursorSigningKey)
+	if len(srv.cursorKey) == 0 {
+		srv.cursorKey = make([]byte, cursorKeySize)
+		if _, err := rand.Read(srv.cursorKey); err != nil {
+			return nil, fmt.Errorf("generate cursor signing key: %w", err)
+		}
+	}
+	if opts.maxNameLength == 0 || opts.maxNameLength > int64(validation.DNS1123SubdomainMaxLength) {
+		return nil, fmt.Errorf("MAX_SECRET_NAME_LENGTH must be between 1 and %d", validation.DNS1123SubdomainMaxLength)
+	}
+	srv.maxNameLength = int(opts.maxNameLength)
+	srv.maxLabels = opts.maxLabels
+	srv.maxAnnotations = opts.maxAnnotations
+	if errs := validation.IsQualifiedName(opts.managedByLabelKey); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid MANAGED_BY_LABEL_KEY %q: %s", opts.managedByLabelKey, strings.Join(errs, ", "))
+	}
+	if opts.legacyManagedByLabelKey != "" {
+		if errs := validation.IsQualifiedName(opts.legacyManagedByLabelKey); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid MANAGED_BY_LEGACY_LABEL_KEY %q: %s", opts.legacyManagedByLabelKey, strings.Join(errs, ", "))
+		}
+		if opts.legacyManagedByLabelKey == opts.managedByLabelKey {
+			return nil, errors.New("MANAGED_BY_LEGACY_LABEL_KEY must differ from MANAGED_BY_LABEL_KEY")
+		}
+	}
+	managedByLabelKey = opts.managedByLabelKey
+	legacyManagedByLabelKey = opts.legacyManagedByLabelKey
+	srv.requiredLabels = make([]string, 0, len(opts.requiredLabels))
+	for _, key := range opts.requiredLabels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid REQUIRED_LABELS key %q: %s", key, strings.Join(errs, ", "))
+		}
+		// The server sets managed-by on every write, so it is always present.
+		if key != opts.managedByLabelKey && key != opts.legacyManagedByLabelKey {
+			srv.requiredLabels = append(srv.requiredLabels, key)
+		}
+	}
+	srv.allowedRegistries = opts.allowedRegistries
+	if srv.registryTestEnabled && len(srv.allowedRegistries) == 0 {
+		return nil, errors.New("REGISTRY_TEST_ENABLED requires ALLOWED_REGISTRIES, since tests call the registries named in user secrets")
+	}
+	srv.rejectNullBytes = opts.rejectNullBytes
+	srv.strictTypeKeys = opts.strictTypeKeys
+	srv.enforceTypeImmutable = opts.enforceTypeImmutable
+	srv.trimStringValues = opts.trimStringValues
+	srv.warningChecks = opts.warningChecks
+	srv.quotaCheckEnabled = opts.quotaCheckEnabled
+	srv.stampVersionEnabled = opts.stampVersion
+	srv.provenanceEnabled = opts.stampProvenance
+	if opts.listMaxLimit <= 0 || opts.listDefaultLimit < 0 || opts.listDefaultLimit > opts.listMaxLimit {
+		return nil, errors.New("LIST_MAX_LIMIT must be positive and LIST_DEFAULT_LIMIT between 0 and LIST_MAX_LIMIT")
+	}
+	srv.listDefaultLimit = opts.listDefaultLimit
+	srv.listMaxLimit = opts.listMaxLimit
+	if opts.importMaxBytes == 0 || opts.importMaxDocuments == 0 {
+		return nil, errors.New("IMPORT_MAX_BYTES and IMPORT_MAX_DOCUMENTS must be positive")
+	}
+	srv.importMaxBytes = opts.importMaxBytes
+	srv.importMaxDocuments = opts.importMaxDocuments
+	srv.importDocumentTimeout = opts.importDocumentTimeout
+	if opts.asyncWorkers > 0 {
+		if opts.asyncQueueSize <= 0 || opts.asyncResultTTL <= 0 || opts.asyncMaxPerUser <= 0 {
+			return nil, errors.New("ASYNC_QUEUE_SIZE, ASYNC_RESULT_TTL and ASYNC_MAX_PER_USER must be positive")
+		}
+		if opts.asyncMaxPerUser >= opts.asyncWorkers {
+			return nil, errors.New("ASYNC_MAX_PER_USER must be below ASYNC_WORKERS so one user cannot occupy every worker")
+		}
+		srv.operations = newOperationStore(opts.asyncWorkers, opts.asyncQueueSize, opts.asyncMaxPerUser, opts.asyncResultTTL)
+	}
+	srv.logKeyNames = opts.logKeyNames
+	srv.profileOwnerPath = opts.profileOwnerPath
+	srv.profileOwnerLabel = opts.profileOwnerLabel
+	srv.profileNamespacePath = opts.profileNamespacePath
+	srv.identityNormalize = opts.identityNormalize
+	srv.hiddenAnnotationPrefixes = opts.hiddenAnnotationPrefixes
+	srv.eventsLimit = opts.eventsLimit
+	srv.eventsWindow = opts.eventsWindow
+	if opts.recentWindowMax <= 0 {
+		return nil, errors.New("RECENT_WINDOW_MAX must be positive")
+	}
+	srv.recentWindowMax = opts.recentWindowMax
+	srv.namespaceAllowlist = stringSet(opts.namespaceAllowlist)
+	srv.namespaceDenylist = stringSet(opts.namespaceDenylist)
+	srv.trustedProxies = opts.trustedProxies
+	if opts.writeLockEnabled {
+		srv.writeLocks = newSecretLocker()
+	}
+	if opts.readDedupEnabled {
+		srv.reads = newReadGroup()
+	}
+	if opts.metricsEnabled {
+		srv.metrics = newMetrics()
+	}
+	if opts.namespaceRecentTracking {
+		srv.recentNamespaces = newNamespaceRecents()
+	}
+	if opts.listSnapshotInterval > 0 {
+		// Snapshots only cover unpaginated lists, which a default limit
+		// turns into paged ones.
+		if opts.listDefaultLimit > 0 {
+			return nil, errors.New("LIST_SNAPSHOT_INTERVAL requires LIST_DEFAULT_LIMIT=0")
+		}
+		srv.snapshots = newListSnapshots(opts.listSnapshotInterval)
+	}
+
+	return srv, nil
 }
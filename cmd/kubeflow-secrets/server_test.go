@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	testUser      = "alice@example.com"
+	testNamespace = "alice"
+)
+
+// newTestServer builds a server from the environment in env, with the admin
+// client swapped for a fake that holds the Profile owning testNamespace.
+// Handlers are called directly with a fake clientset standing in for the
+// impersonated client.
+func newTestServer(t *testing.T, env map[string]string) *server {
+	t.Helper()
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	opts, err := loadServerOptions()
+	if err != nil {
+		t.Fatalf("loadServerOptions: %v", err)
+	}
+	srv, err := newServer(&rest.Config{Host: "https://127.0.0.1:1"}, opts)
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+
+	profile := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "kubeflow.org/v1",
+		"kind":       "Profile",
+		"metadata":   map[string]any{"name": testNamespace},
+		"spec":       map[string]any{"owner": map[string]any{"kind": "User", "name": testUser}},
+	}}
+	srv.adminDynamic = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{srv.profileGVR: "ProfileList"},
+		profile,
+	)
+	return srv
+}
+
+// newTestRequest is a request from testUser.
+func newTestRequest(srv *server, method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set(srv.userHeader, testUser)
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r
+}
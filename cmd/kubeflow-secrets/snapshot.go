@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxListSnapshots bounds the snapshot cache. When it is full and nothing
+// has expired, new results are served but not cached.
+const maxListSnapshots = 1024
+
+const cacheHeader = "X-Cache"
+
+// listSnapshots keeps recent unpaginated list results for
+// LIST_SNAPSHOT_INTERVAL so busy dashboards polling the list do not hit the
+// apiserver on every call. Entries are keyed like dedup reads (identity,
+// namespace, write generation, selector, scope), so a caller only sees what
+// it listed itself and any write through this pod starts a fresh snapshot.
+// Snapshots hold what listManagedSecretPage returns: metadata and key names,
+// never values.
+type listSnapshots struct {
+	mu       sync.Mutex
+	interval time.Duration
+	entries  map[string]listSnapshot
+}
+
+type listSnapshot struct {
+	items   []corev1.Secret
+	fetched time.Time
+}
+
+func newListSnapshots(interval time.Duration) *listSnapshots {
+	return &listSnapshots{interval: interval, entries: make(map[string]listSnapshot)}
+}
+
+func (c *listSnapshots) get(key string, now time.Time) ([]corev1.Secret, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || now.Sub(entry.fetched) >= c.interval {
+		return nil, false
+	}
+	return append([]corev1.Secret(nil), entry.items...), true
+}
+
+func (c *listSnapshots) put(key string, items []corev1.Secret, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxListSnapshots {
+		for k, entry := range c.entries {
+			if now.Sub(entry.fetched) >= c.interval {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxListSnapshots {
+			return
+		}
+	}
+	c.entries[key] = listSnapshot{items: items, fetched: now}
+}
+
+// snapshotListPage serves the first, unpaginated page from the snapshot
+// cache and falls back to dedupListPage for everything else. It sets X-Cache
+// whenever the cache was consulted.
+func (s *server) snapshotListPage(w http.ResponseWriter, r *http.Request, client kubernetes.Interface, namespace, selector string, page listPage) ([]corev1.Secret, string, error) {
+	if s.snapshots == nil || page.limit > 0 || page.continueToken != "" {
+		return s.dedupListPage(r, client, namespace, selector, page)
+	}
+
+	now := time.Now()
+	key := s.readKey(r, "snapshot", namespace, s.writes.generation(namespace), selector, page.scope)
+	if items, ok := s.snapshots.get(key, now); ok {
+		w.Header().Set(cacheHeader, "hit")
+		return items, "", nil
+	}
+
+	items, next, err := s.dedupListPage(r, client, namespace, selector, page)
+	if err != nil {
+		return nil, "", err
+	}
+	w.Header().Set(cacheHeader, "miss")
+	s.snapshots.put(key, items, now)
+	return items, next, nil
+}
@@ -13,7 +13,7 @@ ${e.map((r,i)=>`${i+1}) ${r.toString()}`).join(`
 `],encapsulation:2,changeDetection:0})}return n})(),X_=(()=>{class n{static \u0275fac=function(r){return new(r||n)};static \u0275mod=se({type:n});static \u0275inj=ne({imports:[We,We]})}return n})();var Vp=class{_box;_destroyed=new U;_resizeSubject=new U;_resizeObserver;_elementObservables=new Map;constructor(t){this._box=t,typeof ResizeObserver<"u"&&(this._resizeObserver=new ResizeObserver(e=>this._resizeSubject.next(e)))}observe(t){return this._elementObservables.has(t)||this._elementObservables.set(t,new B(e=>{let r=this._resizeSubject.subscribe(e);return this._resizeObserver?.observe(t,{box:this._box}),()=>{this._resizeObserver?.unobserve(t),r.unsubscribe(),this._elementObservables.delete(t)}}).pipe(Ue(e=>e.some(r=>r.target===t)),da({bufferSize:1,refCount:!0}),st(this._destroyed))),this._elementObservables.get(t)}destroy(){this._destroyed.next(),this._destroyed.complete(),this._resizeSubject.complete(),this._elementObservables.clear()}},J_=(()=>{class n{_cleanupErrorListener;_observers=new Map;_ngZone=p(F);constructor(){typeof ResizeObserver<"u"}ngOnDestroy(){for(let[,e]of this._observers)e.destroy();this._observers.clear(),this._cleanupErrorListener?.()}observe(e,r){let i=r?.box||"content-box";return this._observers.has(i)||this._observers.set(i,new Vp(i)),this._observers.get(i).observe(e)}static \u0275fac=function(r){return new(r||n)};static \u0275prov=w({token:n,factory:n.\u0275fac,providedIn:"root"})}return n})();var ox=20,sx=(()=>{class n{_ngZone=p(F);_platform=p(Re);_renderer=p(He).createRenderer(null,null);_cleanupGlobalListener;constructor(){}_scrolled=new U;_scrolledCount=0;scrollContainers=new Map;register(e){this.scrollContainers.has(e)||this.scrollContainers.set(e,e.elementScrolled().subscribe(()=>this._scrolled.next(e)))}deregister(e){let r=this.scrollContainers.get(e);r&&(r.unsubscribe(),this.scrollContainers.delete(e))}scrolled(e=ox){return this._platform.isBrowser?new B(r=>{this._cleanupGlobalListener||(this._cleanupGlobalListener=this._ngZone.runOutsideAngular(()=>this._renderer.listen("document","scroll",()=>this._scrolled.next())));let i=e>0?this._scrolled.pipe(la(e)).subscribe(r):this._scrolled.subscribe(r);return this._scrolledCount++,()=>{i.unsubscribe(),this._scrolledCount--,this._scrolledCount||(this._cleanupGlobalListener?.(),this._cleanupGlobalListener=void 0)}}):je()}ngOnDestroy(){this._cleanupGlobalListener?.(),this._cleanupGlobalListener=void 0,this.scrollContainers.forEach((e,r)=>this.deregister(r)),this._scrolled.complete()}ancestorScrolled(e,r){let i=this.getAncestorScrollContainers(e);return this.scrolled(r).pipe(Ue(o=>!o||i.indexOf(o)>-1))}getAncestorScrollContainers(e){let r=[];return this.scrollContainers.forEach((i,o)=>{this._scrollableContainsElement(o,e)&&r.push(o)}),r}_scrollableContainsElement(e,r){let i=wt(r),o=e.getElementRef().nativeElement;do if(i==o)return!0;while(i=i.parentElement);return!1}static \u0275fac=function(r){return new(r||n)};static \u0275prov=w({token:n,factory:n.\u0275fac,providedIn:"root"})}return n})(),eE=(()=>{class n{elementRef=p(K);scrollDispatcher=p(sx);ngZone=p(F);dir=p(Qi,{optional:!0});_scrollElement=this.elementRef.nativeElement;_destroyed=new U;_renderer=p(Xe);_cleanupScroll;_elementScrolled=new U;constructor(){}ngOnInit(){this._cleanupScroll=this.ngZone.runOutsideAngular(()=>this._renderer.listen(this._scrollElement,"scroll",e=>this._elementScrolled.next(e))),this.scrollDispatcher.register(this)}ngOnDestroy(){this._cleanupScroll?.(),this._elementScrolled.complete(),this.scrollDispatcher.deregister(this),this._destroyed.next(),this._destroyed.complete()}elementScrolled(){return this._elementScrolled}getElementRef(){return this.elementRef}scrollTo(e){let r=this.elementRef.nativeElement,i=this.dir&&this.dir.value=="rtl";e.left==null&&(e.left=i?e.end:e.start),e.right==null&&(e.right=i?e.start:e.end),e.bottom!=null&&(e.top=r.scrollHeight-r.clientHeight-e.bottom),i&&Ki()!=Ht.NORMAL?(e.left!=null&&(e.right=r.scrollWidth-r.clientWidth-e.left),Ki()==Ht.INVERTED?e.left=e.right:Ki()==Ht.NEGATED&&(e.left=e.right?-e.right:e.right)):e.right!=null&&(e.left=r.scrollWidth-r.clientWidth-e.right),this._applyScrollToOptions(e)}_applyScrollToOptions(e){let r=this.elementRef.nativeElement;x_()?r.scrollTo(e):(e.top!=null&&(r.scrollTop=e.top),e.left!=null&&(r.scrollLeft=e.left))}measureScrollOffset(e){let r="left",i="right",o=this.elementRef.nativeElement;if(e=="top")return o.scrollTop;if(e=="bottom")return o.scrollHeight-o.clientHeight-o.scrollTop;let s=this.dir&&this.dir.value=="rtl";return e=="start"?e=s?i:r:e=="end"&&(e=s?r:i),s&&Ki()==Ht.INVERTED?e==r?o.scrollWidth-o.clientWidth-o.scrollLeft:o.scrollLeft:s&&Ki()==Ht.NEGATED?e==r?o.scrollLeft+o.scrollWidth-o.clientWidth:-o.scrollLeft:e==r?o.scrollLeft:o.scrollWidth-o.clientWidth-o.scrollLeft}static \u0275fac=function(r){return new(r||n)};static \u0275dir=Q({type:n,selectors:[["","cdk-scrollable",""],["","cdkScrollable",""]]})}return n})(),ax=20,tE=(()=>{class n{_platform=p(Re);_listeners;_viewportSize;_change=new U;_document=p(G);constructor(){let e=p(F),r=p(He).createRenderer(null,null);e.runOutsideAngular(()=>{if(this._platform.isBrowser){let i=o=>this._change.next(o);this._listeners=[r.listen("window","resize",i),r.listen("window","orientationchange",i)]}this.change().subscribe(()=>this._viewportSize=null)})}ngOnDestroy(){this._listeners?.forEach(e=>e()),this._change.complete()}getViewportSize(){this._viewportSize||this._updateViewportSize();let e={width:this._viewportSize.width,height:this._viewportSize.height};return this._platform.isBrowser||(this._viewportSize=null),e}getViewportRect(){let e=this.getViewportScrollPosition(),{width:r,height:i}=this.getViewportSize();return{top:e.top,left:e.left,bottom:e.top+i,right:e.left+r,height:i,width:r}}getViewportScrollPosition(){if(!this._platform.isBrowser)return{top:0,left:0};let e=this._document,r=this._getWindow(),i=e.documentElement,o=i.getBoundingClientRect(),s=-o.top||e.body.scrollTop||r.scrollY||i.scrollTop||0,a=-o.left||e.body.scrollLeft||r.scrollX||i.scrollLeft||0;return{top:s,left:a}}change(e=ax){return e>0?this._change.pipe(la(e)):this._change}_getWindow(){return this._document.defaultView||window}_updateViewportSize(){let e=this._getWindow();this._viewportSize=this._platform.isBrowser?{width:e.innerWidth,height:e.innerHeight}:{width:0,height:0}}static \u0275fac=function(r){return new(r||n)};static \u0275prov=w({token:n,factory:n.\u0275fac,providedIn:"root"})}return n})();var _s=class{_attachedHost;attach(t){return this._attachedHost=t,t.attach(this)}detach(){let t=this._attachedHost;t!=null&&(this._attachedHost=null,t.detach())}get isAttached(){return this._attachedHost!=null}setAttachedHost(t){this._attachedHost=t}},jp=class extends _s{component;viewContainerRef;injector;projectableNodes;constructor(t,e,r,i){super(),this.component=t,this.viewContainerRef=e,this.injector=r,this.projectableNodes=i}},Zi=class extends _s{templateRef;viewContainerRef;context;injector;constructor(t,e,r,i){super(),this.templateRef=t,this.viewContainerRef=e,this.context=r,this.injector=i}get origin(){return this.templateRef.elementRef}attach(t,e=this.context){return this.context=e,super.attach(t)}detach(){return this.context=void 0,super.detach()}},Bp=class extends _s{element;constructor(t){super(),this.element=t instanceof K?t.nativeElement:t}},Hp=class{_attachedPortal;_disposeFn;_isDisposed=!1;hasAttached(){return!!this._attachedPortal}attach(t){if(t instanceof jp)return this._attachedPortal=t,this.attachComponentPortal(t);if(t instanceof Zi)return this._attachedPortal=t,this.attachTemplatePortal(t);if(this.attachDomPortal&&t instanceof Bp)return this._attachedPortal=t,this.attachDomPortal(t)}attachDomPortal=null;detach(){this._attachedPortal&&(this._attachedPortal.setAttachedHost(null),this._attachedPortal=null),this._invokeDisposeFn()}dispose(){this.hasAttached()&&this.detach(),this._invokeDisposeFn(),this._isDisposed=!0}setDisposeFn(t){this._disposeFn=t}_invokeDisposeFn(){this._disposeFn&&(this._disposeFn(),this._disposeFn=null)}};var nE=(()=>{class n extends Zi{constructor(){let e=p(ut),r=p(Ft);super(e,r)}static \u0275fac=function(r){return new(r||n)};static \u0275dir=Q({type:n,selectors:[["","cdkPortal",""]],exportAs:["cdkPortal"],features:[Ee]})}return n})();var Up=(()=>{class n extends Hp{_moduleRef=p(Di,{optional:!0});_document=p(G);_viewContainerRef=p(Ft);_isInitialized=!1;_attachedRef;constructor(){super()}get portal(){return this._attachedPortal}set portal(e){this.hasAttached()&&!e&&!this._isInitialized||(this.hasAttached()&&super.detach(),e&&super.attach(e),this._attachedPortal=e||null)}attached=new J;get attachedRef(){return this._attachedRef}ngOnInit(){this._isInitialized=!0}ngOnDestroy(){super.dispose(),this._attachedRef=this._attachedPortal=null}attachComponentPortal(e){e.setAttachedHost(this);let r=e.viewContainerRef!=null?e.viewContainerRef:this._viewContainerRef,i=r.createComponent(e.component,{index:r.length,injector:e.injector||r.injector,projectableNodes:e.projectableNodes||void 0,ngModuleRef:this._moduleRef||void 0});return r!==this._viewContainerRef&&this._getRootNode().appendChild(i.hostView.rootNodes[0]),super.setDisposeFn(()=>i.destroy()),this._attachedPortal=e,this._attachedRef=i,this.attached.emit(i),i}attachTemplatePortal(e){e.setAttachedHost(this);let r=this._viewContainerRef.createEmbeddedView(e.templateRef,e.context,{injector:e.injector});return super.setDisposeFn(()=>this._viewContainerRef.clear()),this._attachedPortal=e,this._attachedRef=r,this.attached.emit(r),r}attachDomPortal=e=>{let r=e.element;r.parentNode;let i=this._document.createComment("dom-portal");e.setAttachedHost(this),r.parentNode.insertBefore(i,r),this._getRootNode().appendChild(r),this._attachedPortal=e,super.setDisposeFn(()=>{i.parentNode&&i.parentNode.replaceChild(r,i)})};_getRootNode(){let e=this._viewContainerRef.element.nativeElement;return e.nodeType===e.ELEMENT_NODE?e:e.parentNode}static \u0275fac=function(r){return new(r||n)};static \u0275dir=Q({type:n,selectors:[["","cdkPortalOutlet",""]],inputs:{portal:[0,"cdkPortalOutlet","portal"]},outputs:{attached:"attached"},exportAs:["cdkPortalOutlet"],features:[Ee]})}return n})();var qp=["*"];function ux(n,t){n&1&&et(0)}var dx=["tabListContainer"],fx=["tabList"],px=["tabListInner"],hx=["nextPaginator"],mx=["previousPaginator"],gx=["content"];function yx(n,t){}var bx=["tabBodyWrapper"],vx=["tabHeader"];function _x(n,t){}function Ex(n,t){if(n&1&&vn(0,_x,0,0,"ng-template",12),n&2){let e=S().$implicit;pe("cdkPortalOutlet",e.templateLabel)}}function Dx(n,t){if(n&1&&D(0),n&2){let e=S().$implicit;be(e.textLabel)}}function Cx(n,t){if(n&1){let e=ft();g(0,"div",7,2),W("click",function(){let i=k(e),o=i.$implicit,s=i.$index,a=S(),l=gl(1);return O(a._handleClick(o,l,s))})("cdkFocusChange",function(i){let o=k(e).$index,s=S();return O(s._tabFocusChanged(i,o))}),Je(2,"span",8)(3,"div",9),g(4,"span",10)(5,"span",11),_t(6,Ex,1,1,null,12)(7,Dx,1,1),b()()()}if(n&2){let e=t.$implicit,r=t.$index,i=gl(1),o=S();En(e.labelClass),ae("mdc-tab--active",o.selectedIndex===r),pe("id",o._getTabLabelId(e,r))("disabled",e.disabled)("fitInkBarToContent",o.fitInkBarToContent),we("tabIndex",o._getTabIndex(r))("aria-posinset",r+1)("aria-setsize",o._tabs.length)("aria-controls",o._getTabContentId(r))("aria-selected",o.selectedIndex===r)("aria-label",e.ariaLabel||null)("aria-labelledby",!e.ariaLabel&&e.ariaLabelledby?e.ariaLabelledby:null),I(3),pe("matRippleTrigger",i)("matRippleDisabled",e.disabled||o.disableRipple),I(3),Et(e.templateLabel?6:7)}}function wx(n,t){n&1&&et(0)}function Ix(n,t){if(n&1){let e=ft();g(0,"mat-tab-body",13),W("_onCentered",function(){k(e);let i=S();return O(i._removeTabBodyWrapperHeight())})("_onCentering",function(i){k(e);let o=S();return O(o._setTabBodyWrapperHeight(i))})("_beforeCentering",function(i){k(e);let o=S();return O(o._bodyCentered(i))}),b()}if(n&2){let e=t.$implicit,r=t.$index,i=S();En(e.bodyClass),pe("id",i._getTabContentId(r))("content",e.content)("position",e.position)("animationDuration",i.animationDuration)("preserveContent",i.preserveContent),we("tabindex",i.contentTabIndex!=null&&i.selectedIndex===r?i.contentTabIndex:null)("aria-labelledby",i._getTabLabelId(e,r))("aria-hidden",i.selectedIndex!==r)}}var Tx=new C("MatTabContent"),Kp=(()=>{class n{template=p(ut);constructor(){}static \u0275fac=function(r){return new(r||n)};static \u0275dir=Q({type:n,selectors:[["","matTabContent",""]],features:[nt([{provide:Tx,useExisting:n}])]})}return n})(),Sx=new C("MatTabLabel"),sE=new C("MAT_TAB"),Mx=(()=>{class n extends nE{_closestTab=p(sE,{optional:!0});static \u0275fac=(()=>{let e;return function(i){return(e||(e=Xt(n)))(i||n)}})();static \u0275dir=Q({type:n,selectors:[["","mat-tab-label",""],["","matTabLabel",""]],features:[nt([{provide:Sx,useExisting:n}]),Ee]})}return n})(),aE=new C("MAT_TAB_GROUP"),Qp=(()=>{class n{_viewContainerRef=p(Ft);_closestTabGroup=p(aE,{optional:!0});disabled=!1;get templateLabel(){return this._templateLabel}set templateLabel(e){this._setTemplateLabelInput(e)}_templateLabel;_explicitContent=void 0;_implicitContent;textLabel="";ariaLabel;ariaLabelledby;labelClass;bodyClass;id=null;_contentPortal=null;get content(){return this._contentPortal}_stateChanges=new U;position=null;origin=null;isActive=!1;constructor(){p(jr).load(pc)}ngOnChanges(e){(e.hasOwnProperty("textLabel")||e.hasOwnProperty("disabled"))&&this._stateChanges.next()}ngOnDestroy(){this._stateChanges.complete()}ngOnInit(){this._contentPortal=new Zi(this._explicitContent||this._implicitContent,this._viewContainerRef)}_setTemplateLabelInput(e){e&&e._closestTab===this&&(this._templateLabel=e)}static \u0275fac=function(r){return new(r||n)};static \u0275cmp=Ce({type:n,selectors:[["mat-tab"]],contentQueries:function(r,i,o){if(r&1&&(ki(o,Mx,5),ki(o,Kp,7,ut)),r&2){let s;Ae(s=Ne())&&(i.templateLabel=s.first),Ae(s=Ne())&&(i._explicitContent=s.first)}},viewQuery:function(r,i){if(r&1&&tt(ut,7),r&2){let o;Ae(o=Ne())&&(i._implicitContent=o.first)}},hostAttrs:["hidden",""],hostVars:1,hostBindings:function(r,i){r&2&&we("id",null)},inputs:{disabled:[2,"disabled","disabled",he],textLabel:[0,"label","textLabel"],ariaLabel:[0,"aria-label","ariaLabel"],ariaLabelledby:[0,"aria-labelledby","ariaLabelledby"],labelClass:"labelClass",bodyClass:"bodyClass",id:"id"},exportAs:["matTab"],features:[nt([{provide:sE,useExisting:n}]),Kn],ngContentSelectors:qp,decls:1,vars:0,template:function(r,i){r&1&&(Dt(),pl(0,ux,1,0,"ng-template"))},encapsulation:2})}return n})(),$p="mdc-tab-indicator--active",rE="mdc-tab-indicator--no-transition",zp=class{_items;_currentItem;constructor(t){this._items=t}hide(){this._items.forEach(t=>t.deactivateInkBar()),this._currentItem=void 0}alignToElement(t){let e=this._items.find(i=>i.elementRef.nativeElement===t),r=this._currentItem;if(e!==r&&(r?.deactivateInkBar(),e)){let i=r?.elementRef.nativeElement.getBoundingClientRect?.();e.activateInkBar(i),this._currentItem=e}}},xx=(()=>{class n{_elementRef=p(K);_inkBarElement;_inkBarContentElement;_fitToContent=!1;get fitInkBarToContent(){return this._fitToContent}set fitInkBarToContent(e){this._fitToContent!==e&&(this._fitToContent=e,this._inkBarElement&&this._appendInkBarElement())}activateInkBar(e){let r=this._elementRef.nativeElement;if(!e||!r.getBoundingClientRect||!this._inkBarContentElement){r.classList.add($p);return}let i=r.getBoundingClientRect(),o=e.width/i.width,s=e.left-i.left;r.classList.add(rE),this._inkBarContentElement.style.setProperty("transform",`translateX(${s}px) scaleX(${o})`),r.getBoundingClientRect(),r.classList.remove(rE),r.classList.add($p),this._inkBarContentElement.style.setProperty("transform","")}deactivateInkBar(){this._elementRef.nativeElement.classList.remove($p)}ngOnInit(){this._createInkBarElement()}ngOnDestroy(){this._inkBarElement?.remove(),this._inkBarElement=this._inkBarContentElement=null}_createInkBarElement(){let e=this._elementRef.nativeElement.ownerDocument||document,r=this._inkBarElement=e.createElement("span"),i=this._inkBarContentElement=e.createElement("span");r.className="mdc-tab-indicator",i.className="mdc-tab-indicator__content mdc-tab-indicator__content--underline",r.appendChild(this._inkBarContentElement),this._appendInkBarElement()}_appendInkBarElement(){this._inkBarElement;let e=this._fitToContent?this._elementRef.nativeElement.querySelector(".mdc-tab__content"):this._elementRef.nativeElement;e.appendChild(this._inkBarElement)}static \u0275fac=function(r){return new(r||n)};static \u0275dir=Q({type:n,inputs:{fitInkBarToContent:[2,"fitInkBarToContent","fitInkBarToContent",he]}})}return n})();var lE=(()=>{class n extends xx{elementRef=p(K);disabled=!1;focus(){this.elementRef.nativeElement.focus()}getOffsetLeft(){return this.elementRef.nativeElement.offsetLeft}getOffsetWidth(){return this.elementRef.nativeElement.offsetWidth}static \u0275fac=(()=>{let e;return function(i){return(e||(e=Xt(n)))(i||n)}})();static \u0275dir=Q({type:n,selectors:[["","matTabLabelWrapper",""]],hostVars:3,hostBindings:function(r,i){r&2&&(we("aria-disabled",!!i.disabled),ae("mat-mdc-tab-disabled",i.disabled))},inputs:{disabled:[2,"disabled","disabled",he]},features:[Ee]})}return n})(),iE={passive:!0},Ax=650,Nx=100,Rx=(()=>{class n{_elementRef=p(K);_changeDetectorRef=p(Tn);_viewportRuler=p(tE);_dir=p(Qi,{optional:!0});_ngZone=p(F);_platform=p(Re);_sharedResizeObserver=p(J_);_injector=p(fe);_renderer=p(Xe);_animationsDisabled=rn();_eventCleanups;_scrollDistance=0;_selectedIndexChanged=!1;_destroyed=new U;_showPaginationControls=!1;_disableScrollAfter=!0;_disableScrollBefore=!0;_tabLabelCount;_scrollDistanceChanged;_keyManager;_currentTextContent;_stopScrolling=new U;disablePagination=!1;get selectedIndex(){return this._selectedIndex}set selectedIndex(e){let r=isNaN(e)?0:e;this._selectedIndex!=r&&(this._selectedIndexChanged=!0,this._selectedIndex=r,this._keyManager&&this._keyManager.updateActiveItem(r))}_selectedIndex=0;selectFocusedIndex=new J;indexFocused=new J;constructor(){this._eventCleanups=this._ngZone.runOutsideAngular(()=>[this._renderer.listen(this._elementRef.nativeElement,"mouseleave",()=>this._stopInterval())])}ngAfterViewInit(){this._eventCleanups.push(this._renderer.listen(this._previousPaginator.nativeElement,"touchstart",()=>this._handlePaginatorPress("before"),iE),this._renderer.listen(this._nextPaginator.nativeElement,"touchstart",()=>this._handlePaginatorPress("after"),iE))}ngAfterContentInit(){let e=this._dir?this._dir.change:je("ltr"),r=this._sharedResizeObserver.observe(this._elementRef.nativeElement).pipe(on(32),st(this._destroyed)),i=this._viewportRuler.change(150).pipe(st(this._destroyed)),o=()=>{this.updatePagination(),this._alignInkBarToSelectedTab()};this._keyManager=new gs(this._items).withHorizontalOrientation(this._getLayoutDirection()).withHomeAndEnd().withWrap().skipPredicate(()=>!1),this._keyManager.updateActiveItem(Math.max(this._selectedIndex,0)),bn(o,{injector:this._injector}),so(e,i,r,this._items.changes,this._itemsResized()).pipe(st(this._destroyed)).subscribe(()=>{this._ngZone.run(()=>{Promise.resolve().then(()=>{this._scrollDistance=Math.max(0,Math.min(this._getMaxScrollDistance(),this._scrollDistance)),o()})}),this._keyManager?.withHorizontalOrientation(this._getLayoutDirection())}),this._keyManager.change.subscribe(s=>{this.indexFocused.emit(s),this._setTabFocus(s)})}_itemsResized(){return typeof ResizeObserver!="function"?ar:this._items.changes.pipe(Fn(this._items),ei(e=>new B(r=>this._ngZone.runOutsideAngular(()=>{let i=new ResizeObserver(o=>r.next(o));return e.forEach(o=>i.observe(o.elementRef.nativeElement)),()=>{i.disconnect()}}))),cr(1),Ue(e=>e.some(r=>r.contentRect.width>0&&r.contentRect.height>0)))}ngAfterContentChecked(){this._tabLabelCount!=this._items.length&&(this.updatePagination(),this._tabLabelCount=this._items.length,this._changeDetectorRef.markForCheck()),this._selectedIndexChanged&&(this._scrollToLabel(this._selectedIndex),this._checkScrollingControls(),this._alignInkBarToSelectedTab(),this._selectedIndexChanged=!1,this._changeDetectorRef.markForCheck()),this._scrollDistanceChanged&&(this._updateTabScrollPosition(),this._scrollDistanceChanged=!1,this._changeDetectorRef.markForCheck())}ngOnDestroy(){this._eventCleanups.forEach(e=>e()),this._keyManager?.destroy(),this._destroyed.next(),this._destroyed.complete(),this._stopScrolling.complete()}_handleKeydown(e){if(!lc(e))switch(e.keyCode){case 13:case 32:if(this.focusIndex!==this.selectedIndex){let r=this._items.get(this.focusIndex);r&&!r.disabled&&(this.selectFocusedIndex.emit(this.focusIndex),this._itemSelected(e))}break;default:this._keyManager?.onKeydown(e)}}_onContentChanges(){let e=this._elementRef.nativeElement.textContent;e!==this._currentTextContent&&(this._currentTextContent=e||"",this._ngZone.run(()=>{this.updatePagination(),this._alignInkBarToSelectedTab(),this._changeDetectorRef.markForCheck()}))}updatePagination(){this._checkPaginationEnabled(),this._checkScrollingControls(),this._updateTabScrollPosition()}get focusIndex(){return this._keyManager?this._keyManager.activeItemIndex:0}set focusIndex(e){!this._isValidIndex(e)||this.focusIndex===e||!this._keyManager||this._keyManager.setActiveItem(e)}_isValidIndex(e){return this._items?!!this._items.toArray()[e]:!0}_setTabFocus(e){if(this._showPaginationControls&&this._scrollToLabel(e),this._items&&this._items.length){this._items.toArray()[e].focus();let r=this._tabListContainer.nativeElement;this._getLayoutDirection()=="ltr"?r.scrollLeft=0:r.scrollLeft=r.scrollWidth-r.offsetWidth}}_getLayoutDirection(){return this._dir&&this._dir.value==="rtl"?"rtl":"ltr"}_updateTabScrollPosition(){if(this.disablePagination)return;let e=this.scrollDistance,r=this._getLayoutDirection()==="ltr"?-e:e;this._tabList.nativeElement.style.transform=`translateX(${Math.round(r)}px)`,(this._platform.TRIDENT||this._platform.EDGE)&&(this._tabListContainer.nativeElement.scrollLeft=0)}get scrollDistance(){return this._scrollDistance}set scrollDistance(e){this._scrollTo(e)}_scrollHeader(e){let r=this._tabListContainer.nativeElement.offsetWidth,i=(e=="before"?-1:1)*r/3;return this._scrollTo(this._scrollDistance+i)}_handlePaginatorClick(e){this._stopInterval(),this._scrollHeader(e)}_scrollToLabel(e){if(this.disablePagination)return;let r=this._items?this._items.toArray()[e]:null;if(!r)return;let i=this._tabListContainer.nativeElement.offsetWidth,{offsetLeft:o,offsetWidth:s}=r.elementRef.nativeElement,a,l;this._getLayoutDirection()=="ltr"?(a=o,l=a+s):(l=this._tabListInner.nativeElement.offsetWidth-o,a=l-s);let c=this.scrollDistance,u=this.scrollDistance+i;a<c?this.scrollDistance-=c-a:l>u&&(this.scrollDistance+=Math.min(l-u,a-c))}_checkPaginationEnabled(){if(this.disablePagination)this._showPaginationControls=!1;else{let e=this._tabListInner.nativeElement.scrollWidth,r=this._elementRef.nativeElement.offsetWidth,i=e-r>=5;i||(this.scrollDistance=0),i!==this._showPaginationControls&&(this._showPaginationControls=i,this._changeDetectorRef.markForCheck())}}_checkScrollingControls(){this.disablePagination?this._disableScrollAfter=this._disableScrollBefore=!0:(this._disableScrollBefore=this.scrollDistance==0,this._disableScrollAfter=this.scrollDistance==this._getMaxScrollDistance(),this._changeDetectorRef.markForCheck())}_getMaxScrollDistance(){let e=this._tabListInner.nativeElement.scrollWidth,r=this._tabListContainer.nativeElement.offsetWidth;return e-r||0}_alignInkBarToSelectedTab(){let e=this._items&&this._items.length?this._items.toArray()[this.selectedIndex]:null,r=e?e.elementRef.nativeElement:null;r?this._inkBar.alignToElement(r):this._inkBar.hide()}_stopInterval(){this._stopScrolling.next()}_handlePaginatorPress(e,r){r&&r.button!=null&&r.button!==0||(this._stopInterval(),oo(Ax,Nx).pipe(st(so(this._stopScrolling,this._destroyed))).subscribe(()=>{let{maxScrollDistance:i,distance:o}=this._scrollHeader(e);(o===0||o>=i)&&this._stopInterval()}))}_scrollTo(e){if(this.disablePagination)return{maxScrollDistance:0,distance:0};let r=this._getMaxScrollDistance();return this._scrollDistance=Math.max(0,Math.min(r,e)),this._scrollDistanceChanged=!0,this._checkScrollingControls(),{maxScrollDistance:r,distance:this._scrollDistance}}static \u0275fac=function(r){return new(r||n)};static \u0275dir=Q({type:n,inputs:{disablePagination:[2,"disablePagination","disablePagination",he],selectedIndex:[2,"selectedIndex","selectedIndex",Fi]},outputs:{selectFocusedIndex:"selectFocusedIndex",indexFocused:"indexFocused"}})}return n})(),kx=(()=>{class n extends Rx{_items;_tabListContainer;_tabList;_tabListInner;_nextPaginator;_previousPaginator;_inkBar;ariaLabel;ariaLabelledby;disableRipple=!1;ngAfterContentInit(){this._inkBar=new zp(this._items),super.ngAfterContentInit()}_itemSelected(e){e.preventDefault()}static \u0275fac=(()=>{let e;return function(i){return(e||(e=Xt(n)))(i||n)}})();static \u0275cmp=Ce({type:n,selectors:[["mat-tab-header"]],contentQueries:function(r,i,o){if(r&1&&ki(o,lE,4),r&2){let s;Ae(s=Ne())&&(i._items=s)}},viewQuery:function(r,i){if(r&1&&(tt(dx,7),tt(fx,7),tt(px,7),tt(hx,5),tt(mx,5)),r&2){let o;Ae(o=Ne())&&(i._tabListContainer=o.first),Ae(o=Ne())&&(i._tabList=o.first),Ae(o=Ne())&&(i._tabListInner=o.first),Ae(o=Ne())&&(i._nextPaginator=o.first),Ae(o=Ne())&&(i._previousPaginator=o.first)}},hostAttrs:[1,"mat-mdc-tab-header"],hostVars:4,hostBindings:function(r,i){r&2&&ae("mat-mdc-tab-header-pagination-controls-enabled",i._showPaginationControls)("mat-mdc-tab-header-rtl",i._getLayoutDirection()=="rtl")},inputs:{ariaLabel:[0,"aria-label","ariaLabel"],ariaLabelledby:[0,"aria-labelledby","ariaLabelledby"],disableRipple:[2,"disableRipple","disableRipple",he]},features:[Ee],ngContentSelectors:qp,decls:13,vars:10,consts:[["previousPaginator",""],["tabListContainer",""],["tabList",""],["tabListInner",""],["nextPaginator",""],["mat-ripple","",1,"mat-mdc-tab-header-pagination","mat-mdc-tab-header-pagination-before",3,"click","mousedown","touchend","matRippleDisabled"],[1,"mat-mdc-tab-header-pagination-chevron"],[1,"mat-mdc-tab-label-container",3,"keydown"],["role","tablist",1,"mat-mdc-tab-list",3,"cdkObserveContent"],[1,"mat-mdc-tab-labels"],["mat-ripple","",1,"mat-mdc-tab-header-pagination","mat-mdc-tab-header-pagination-after",3,"mousedown","click","touchend","matRippleDisabled"]],template:function(r,i){if(r&1){let o=ft();Dt(),g(0,"div",5,0),W("click",function(){return k(o),O(i._handlePaginatorClick("before"))})("mousedown",function(a){return k(o),O(i._handlePaginatorPress("before",a))})("touchend",function(){return k(o),O(i._stopInterval())}),Je(2,"div",6),b(),g(3,"div",7,1),W("keydown",function(a){return k(o),O(i._handleKeydown(a))}),g(5,"div",8,2),W("cdkObserveContent",function(){return k(o),O(i._onContentChanges())}),g(7,"div",9,3),et(9),b()()(),g(10,"div",10,4),W("mousedown",function(a){return k(o),O(i._handlePaginatorPress("after",a))})("click",function(){return k(o),O(i._handlePaginatorClick("after"))})("touchend",function(){return k(o),O(i._stopInterval())}),Je(12,"div",6),b()}r&2&&(ae("mat-mdc-tab-header-pagination-disabled",i._disableScrollBefore),pe("matRippleDisabled",i._disableScrollBefore||i.disableRipple),I(3),ae("_mat-animation-noopable",i._animationsDisabled),I(2),we("aria-label",i.ariaLabel||null)("aria-labelledby",i.ariaLabelledby||null),I(5),ae("mat-mdc-tab-header-pagination-disabled",i._disableScrollAfter),pe("matRippleDisabled",i._disableScrollAfter||i.disableRipple))},dependencies:[Rp,T_],styles:[`.mat-mdc-tab-header{display:flex;overflow:hidden;position:relative;flex-shrink:0}.mdc-tab-indicator .mdc-tab-indicator__content{transition-duration:var(--mat-tab-animation-duration, 250ms)}.mat-mdc-tab-header-pagination{-webkit-user-select:none;user-select:none;position:relative;display:none;justify-content:center;align-items:center;min-width:32px;cursor:pointer;z-index:2;-webkit-tap-highlight-color:rgba(0,0,0,0);touch-action:none;box-sizing:content-box;outline:0}.mat-mdc-tab-header-pagination::-moz-focus-inner{border:0}.mat-mdc-tab-header-pagination .mat-ripple-element{opacity:.12;background-color:var(--mat-tab-inactive-ripple-color, var(--mat-sys-on-surface))}.mat-mdc-tab-header-pagination-controls-enabled .mat-mdc-tab-header-pagination{display:flex}.mat-mdc-tab-header-pagination-before,.mat-mdc-tab-header-rtl .mat-mdc-tab-header-pagination-after{padding-left:4px}.mat-mdc-tab-header-pagination-before .mat-mdc-tab-header-pagination-chevron,.mat-mdc-tab-header-rtl .mat-mdc-tab-header-pagination-after .mat-mdc-tab-header-pagination-chevron{transform:rotate(-135deg)}.mat-mdc-tab-header-rtl .mat-mdc-tab-header-pagination-before,.mat-mdc-tab-header-pagination-after{padding-right:4px}.mat-mdc-tab-header-rtl .mat-mdc-tab-header-pagination-before .mat-mdc-tab-header-pagination-chevron,.mat-mdc-tab-header-pagination-after .mat-mdc-tab-header-pagination-chevron{transform:rotate(45deg)}.mat-mdc-tab-header-pagination-chevron{border-style:solid;border-width:2px 2px 0 0;height:8px;width:8px;border-color:var(--mat-tab-pagination-icon-color, var(--mat-sys-on-surface))}.mat-mdc-tab-header-pagination-disabled{box-shadow:none;cursor:default;pointer-events:none}.mat-mdc-tab-header-pagination-disabled .mat-mdc-tab-header-pagination-chevron{opacity:.4}.mat-mdc-tab-list{flex-grow:1;position:relative;transition:transform 500ms cubic-bezier(0.35, 0, 0.25, 1)}._mat-animation-noopable .mat-mdc-tab-list{transition:none}.mat-mdc-tab-label-container{display:flex;flex-grow:1;overflow:hidden;z-index:1;border-bottom-style:solid;border-bottom-width:var(--mat-tab-divider-height, 1px);border-bottom-color:var(--mat-tab-divider-color, var(--mat-sys-surface-variant))}.mat-mdc-tab-group-inverted-header .mat-mdc-tab-label-container{border-bottom:none;border-top-style:solid;border-top-width:var(--mat-tab-divider-height, 1px);border-top-color:var(--mat-tab-divider-color, var(--mat-sys-surface-variant))}.mat-mdc-tab-labels{display:flex;flex:1 0 auto}[mat-align-tabs=center]>.mat-mdc-tab-header .mat-mdc-tab-labels{justify-content:center}[mat-align-tabs=end]>.mat-mdc-tab-header .mat-mdc-tab-labels{justify-content:flex-end}.cdk-drop-list .mat-mdc-tab-labels,.mat-mdc-tab-labels.cdk-drop-list{min-height:var(--mat-tab-container-height, 48px)}.mat-mdc-tab::before{margin:5px}@media(forced-colors: active){.mat-mdc-tab[aria-disabled=true]{color:GrayText}}
 `],encapsulation:2})}return n})(),Ox=new C("MAT_TABS_CONFIG"),oE=(()=>{class n extends Up{_host=p(Gp);_ngZone=p(F);_centeringSub=te.EMPTY;_leavingSub=te.EMPTY;constructor(){super()}ngOnInit(){super.ngOnInit(),this._centeringSub=this._host._beforeCentering.pipe(Fn(this._host._isCenterPosition())).subscribe(e=>{this._host._content&&e&&!this.hasAttached()&&this._ngZone.run(()=>{Promise.resolve().then(),this.attach(this._host._content)})}),this._leavingSub=this._host._afterLeavingCenter.subscribe(()=>{this._host.preserveContent||this._ngZone.run(()=>this.detach())})}ngOnDestroy(){super.ngOnDestroy(),this._centeringSub.unsubscribe(),this._leavingSub.unsubscribe()}static \u0275fac=function(r){return new(r||n)};static \u0275dir=Q({type:n,selectors:[["","matTabBodyHost",""]],features:[Ee]})}return n})(),Gp=(()=>{class n{_elementRef=p(K);_dir=p(Qi,{optional:!0});_ngZone=p(F);_injector=p(fe);_renderer=p(Xe);_diAnimationsDisabled=rn();_eventCleanups;_initialized;_fallbackTimer;_positionIndex;_dirChangeSubscription=te.EMPTY;_position;_previousPosition;_onCentering=new J;_beforeCentering=new J;_afterLeavingCenter=new J;_onCentered=new J(!0);_portalHost;_contentElement;_content;animationDuration="500ms";preserveContent=!1;set position(e){this._positionIndex=e,this._computePositionAnimationState()}constructor(){if(this._dir){let e=p(Tn);this._dirChangeSubscription=this._dir.change.subscribe(r=>{this._computePositionAnimationState(r),e.markForCheck()})}}ngOnInit(){this._bindTransitionEvents(),this._position==="center"&&(this._setActiveClass(!0),bn(()=>this._onCentering.emit(this._elementRef.nativeElement.clientHeight),{injector:this._injector})),this._initialized=!0}ngOnDestroy(){clearTimeout(this._fallbackTimer),this._eventCleanups?.forEach(e=>e()),this._dirChangeSubscription.unsubscribe()}_bindTransitionEvents(){this._ngZone.runOutsideAngular(()=>{let e=this._elementRef.nativeElement,r=i=>{i.target===this._contentElement?.nativeElement&&(this._elementRef.nativeElement.classList.remove("mat-tab-body-animating"),i.type==="transitionend"&&this._transitionDone())};this._eventCleanups=[this._renderer.listen(e,"transitionstart",i=>{i.target===this._contentElement?.nativeElement&&(this._elementRef.nativeElement.classList.add("mat-tab-body-animating"),this._transitionStarted())}),this._renderer.listen(e,"transitionend",r),this._renderer.listen(e,"transitioncancel",r)]})}_transitionStarted(){clearTimeout(this._fallbackTimer);let e=this._position==="center";this._beforeCentering.emit(e),e&&this._onCentering.emit(this._elementRef.nativeElement.clientHeight)}_transitionDone(){this._position==="center"?this._onCentered.emit():this._previousPosition==="center"&&this._afterLeavingCenter.emit()}_setActiveClass(e){this._elementRef.nativeElement.classList.toggle("mat-mdc-tab-body-active",e)}_getLayoutDirection(){return this._dir&&this._dir.value==="rtl"?"rtl":"ltr"}_isCenterPosition(){return this._positionIndex===0}_computePositionAnimationState(e=this._getLayoutDirection()){this._previousPosition=this._position,this._positionIndex<0?this._position=e=="ltr"?"left":"right":this._positionIndex>0?this._position=e=="ltr"?"right":"left":this._position="center",this._animationsDisabled()?this._simulateTransitionEvents():this._initialized&&(this._position==="center"||this._previousPosition==="center")&&(clearTimeout(this._fallbackTimer),this._fallbackTimer=this._ngZone.runOutsideAngular(()=>setTimeout(()=>this._simulateTransitionEvents(),100)))}_simulateTransitionEvents(){this._transitionStarted(),bn(()=>this._transitionDone(),{injector:this._injector})}_animationsDisabled(){return this._diAnimationsDisabled||this.animationDuration==="0ms"||this.animationDuration==="0s"}static \u0275fac=function(r){return new(r||n)};static \u0275cmp=Ce({type:n,selectors:[["mat-tab-body"]],viewQuery:function(r,i){if(r&1&&(tt(oE,5),tt(gx,5)),r&2){let o;Ae(o=Ne())&&(i._portalHost=o.first),Ae(o=Ne())&&(i._contentElement=o.first)}},hostAttrs:[1,"mat-mdc-tab-body"],hostVars:1,hostBindings:function(r,i){r&2&&we("inert",i._position==="center"?null:"")},inputs:{_content:[0,"content","_content"],animationDuration:"animationDuration",preserveContent:"preserveContent",position:"position"},outputs:{_onCentering:"_onCentering",_beforeCentering:"_beforeCentering",_onCentered:"_onCentered"},decls:3,vars:6,consts:[["content",""],["cdkScrollable","",1,"mat-mdc-tab-body-content"],["matTabBodyHost",""]],template:function(r,i){r&1&&(g(0,"div",1,0),vn(2,yx,0,0,"ng-template",2),b()),r&2&&ae("mat-tab-body-content-left",i._position==="left")("mat-tab-body-content-right",i._position==="right")("mat-tab-body-content-can-animate",i._position==="center"||i._previousPosition==="center")},dependencies:[oE,eE],styles:[`.mat-mdc-tab-body{top:0;left:0;right:0;bottom:0;position:absolute;display:block;overflow:hidden;outline:0;flex-basis:100%}.mat-mdc-tab-body.mat-mdc-tab-body-active{position:relative;overflow-x:hidden;overflow-y:auto;z-index:1;flex-grow:1}.mat-mdc-tab-group.mat-mdc-tab-group-dynamic-height .mat-mdc-tab-body.mat-mdc-tab-body-active{overflow-y:hidden}.mat-mdc-tab-body-content{height:100%;overflow:auto;transform:none;visibility:hidden}.mat-tab-body-animating>.mat-mdc-tab-body-content,.mat-mdc-tab-body-active>.mat-mdc-tab-body-content{visibility:visible}.mat-tab-body-animating>.mat-mdc-tab-body-content{min-height:1px}.mat-mdc-tab-group-dynamic-height .mat-mdc-tab-body-content{overflow:hidden}.mat-tab-body-content-can-animate{transition:transform var(--mat-tab-animation-duration) 1ms cubic-bezier(0.35, 0, 0.25, 1)}.mat-mdc-tab-body-wrapper._mat-animation-noopable .mat-tab-body-content-can-animate{transition:none}.mat-tab-body-content-left{transform:translate3d(-100%, 0, 0)}.mat-tab-body-content-right{transform:translate3d(100%, 0, 0)}
 `],encapsulation:2})}return n})(),cE=(()=>{class n{_elementRef=p(K);_changeDetectorRef=p(Tn);_ngZone=p(F);_tabsSubscription=te.EMPTY;_tabLabelSubscription=te.EMPTY;_tabBodySubscription=te.EMPTY;_diAnimationsDisabled=rn();_allTabs;_tabBodies;_tabBodyWrapper;_tabHeader;_tabs=new fn;_indexToSelect=0;_lastFocusedTabIndex=null;_tabBodyWrapperHeight=0;color;get fitInkBarToContent(){return this._fitInkBarToContent}set fitInkBarToContent(e){this._fitInkBarToContent=e,this._changeDetectorRef.markForCheck()}_fitInkBarToContent=!1;stretchTabs=!0;alignTabs=null;dynamicHeight=!1;get selectedIndex(){return this._selectedIndex}set selectedIndex(e){this._indexToSelect=isNaN(e)?null:e}_selectedIndex=null;headerPosition="above";get animationDuration(){return this._animationDuration}set animationDuration(e){let r=e+"";this._animationDuration=/^\d+$/.test(r)?e+"ms":r}_animationDuration;get contentTabIndex(){return this._contentTabIndex}set contentTabIndex(e){this._contentTabIndex=isNaN(e)?null:e}_contentTabIndex;disablePagination=!1;disableRipple=!1;preserveContent=!1;get backgroundColor(){return this._backgroundColor}set backgroundColor(e){let r=this._elementRef.nativeElement.classList;r.remove("mat-tabs-with-background",`mat-background-${this.backgroundColor}`),e&&r.add("mat-tabs-with-background",`mat-background-${e}`),this._backgroundColor=e}_backgroundColor;ariaLabel;ariaLabelledby;selectedIndexChange=new J;focusChange=new J;animationDone=new J;selectedTabChange=new J(!0);_groupId;_isServer=!p(Re).isBrowser;constructor(){let e=p(Ox,{optional:!0});this._groupId=p(Mp).getId("mat-tab-group-"),this.animationDuration=e&&e.animationDuration?e.animationDuration:"500ms",this.disablePagination=e&&e.disablePagination!=null?e.disablePagination:!1,this.dynamicHeight=e&&e.dynamicHeight!=null?e.dynamicHeight:!1,e?.contentTabIndex!=null&&(this.contentTabIndex=e.contentTabIndex),this.preserveContent=!!e?.preserveContent,this.fitInkBarToContent=e&&e.fitInkBarToContent!=null?e.fitInkBarToContent:!1,this.stretchTabs=e&&e.stretchTabs!=null?e.stretchTabs:!0,this.alignTabs=e&&e.alignTabs!=null?e.alignTabs:null}ngAfterContentChecked(){let e=this._indexToSelect=this._clampTabIndex(this._indexToSelect);if(this._selectedIndex!=e){let r=this._selectedIndex==null;if(!r){this.selectedTabChange.emit(this._createChangeEvent(e));let i=this._tabBodyWrapper.nativeElement;i.style.minHeight=i.clientHeight+"px"}Promise.resolve().then(()=>{this._tabs.forEach((i,o)=>i.isActive=o===e),r||(this.selectedIndexChange.emit(e),this._tabBodyWrapper.nativeElement.style.minHeight="")})}this._tabs.forEach((r,i)=>{r.position=i-e,this._selectedIndex!=null&&r.position==0&&!r.origin&&(r.origin=e-this._selectedIndex)}),this._selectedIndex!==e&&(this._selectedIndex=e,this._lastFocusedTabIndex=null,this._changeDetectorRef.markForCheck())}ngAfterContentInit(){this._subscribeToAllTabChanges(),this._subscribeToTabLabels(),this._tabsSubscription=this._tabs.changes.subscribe(()=>{let e=this._clampTabIndex(this._indexToSelect);if(e===this._selectedIndex){let r=this._tabs.toArray(),i;for(let o=0;o<r.length;o++)if(r[o].isActive){this._indexToSelect=this._selectedIndex=o,this._lastFocusedTabIndex=null,i=r[o];break}!i&&r[e]&&Promise.resolve().then(()=>{r[e].isActive=!0,this.selectedTabChange.emit(this._createChangeEvent(e))})}this._changeDetectorRef.markForCheck()})}ngAfterViewInit(){this._tabBodySubscription=this._tabBodies.changes.subscribe(()=>this._bodyCentered(!0))}_subscribeToAllTabChanges(){this._allTabs.changes.pipe(Fn(this._allTabs)).subscribe(e=>{this._tabs.reset(e.filter(r=>r._closestTabGroup===this||!r._closestTabGroup)),this._tabs.notifyOnChanges()})}ngOnDestroy(){this._tabs.destroy(),this._tabsSubscription.unsubscribe(),this._tabLabelSubscription.unsubscribe(),this._tabBodySubscription.unsubscribe()}realignInkBar(){this._tabHeader&&this._tabHeader._alignInkBarToSelectedTab()}updatePagination(){this._tabHeader&&this._tabHeader.updatePagination()}focusTab(e){let r=this._tabHeader;r&&(r.focusIndex=e)}_focusChanged(e){this._lastFocusedTabIndex=e,this.focusChange.emit(this._createChangeEvent(e))}_createChangeEvent(e){let r=new Wp;return r.index=e,this._tabs&&this._tabs.length&&(r.tab=this._tabs.toArray()[e]),r}_subscribeToTabLabels(){this._tabLabelSubscription&&this._tabLabelSubscription.unsubscribe(),this._tabLabelSubscription=so(...this._tabs.map(e=>e._stateChanges)).subscribe(()=>this._changeDetectorRef.markForCheck())}_clampTabIndex(e){return Math.min(this._tabs.length-1,Math.max(e||0,0))}_getTabLabelId(e,r){return e.id||`${this._groupId}-label-${r}`}_getTabContentId(e){return`${this._groupId}-content-${e}`}_setTabBodyWrapperHeight(e){if(!this.dynamicHeight||!this._tabBodyWrapperHeight){this._tabBodyWrapperHeight=e;return}let r=this._tabBodyWrapper.nativeElement;r.style.height=this._tabBodyWrapperHeight+"px",this._tabBodyWrapper.nativeElement.offsetHeight&&(r.style.height=e+"px")}_removeTabBodyWrapperHeight(){let e=this._tabBodyWrapper.nativeElement;this._tabBodyWrapperHeight=e.clientHeight,e.style.height="",this._ngZone.run(()=>this.animationDone.emit())}_handleClick(e,r,i){r.focusIndex=i,e.disabled||(this.selectedIndex=i)}_getTabIndex(e){let r=this._lastFocusedTabIndex??this.selectedIndex;return e===r?0:-1}_tabFocusChanged(e,r){e&&e!=="mouse"&&e!=="touch"&&(this._tabHeader.focusIndex=r)}_bodyCentered(e){e&&this._tabBodies?.forEach((r,i)=>r._setActiveClass(i===this._selectedIndex))}_animationsDisabled(){return this._diAnimationsDisabled||this.animationDuration==="0"||this.animationDuration==="0ms"}static \u0275fac=function(r){return new(r||n)};static \u0275cmp=Ce({type:n,selectors:[["mat-tab-group"]],contentQueries:function(r,i,o){if(r&1&&ki(o,Qp,5),r&2){let s;Ae(s=Ne())&&(i._allTabs=s)}},viewQuery:function(r,i){if(r&1&&(tt(bx,5),tt(vx,5),tt(Gp,5)),r&2){let o;Ae(o=Ne())&&(i._tabBodyWrapper=o.first),Ae(o=Ne())&&(i._tabHeader=o.first),Ae(o=Ne())&&(i._tabBodies=o)}},hostAttrs:[1,"mat-mdc-tab-group"],hostVars:11,hostBindings:function(r,i){r&2&&(we("mat-align-tabs",i.alignTabs),En("mat-"+(i.color||"primary")),Vo("--mat-tab-animation-duration",i.animationDuration),ae("mat-mdc-tab-group-dynamic-height",i.dynamicHeight)("mat-mdc-tab-group-inverted-header",i.headerPosition==="below")("mat-mdc-tab-group-stretch-tabs",i.stretchTabs))},inputs:{color:"color",fitInkBarToContent:[2,"fitInkBarToContent","fitInkBarToContent",he],stretchTabs:[2,"mat-stretch-tabs","stretchTabs",he],alignTabs:[0,"mat-align-tabs","alignTabs"],dynamicHeight:[2,"dynamicHeight","dynamicHeight",he],selectedIndex:[2,"selectedIndex","selectedIndex",Fi],headerPosition:"headerPosition",animationDuration:"animationDuration",contentTabIndex:[2,"contentTabIndex","contentTabIndex",Fi],disablePagination:[2,"disablePagination","disablePagination",he],disableRipple:[2,"disableRipple","disableRipple",he],preserveContent:[2,"preserveContent","preserveContent",he],backgroundColor:"backgroundColor",ariaLabel:[0,"aria-label","ariaLabel"],ariaLabelledby:[0,"aria-labelledby","ariaLabelledby"]},outputs:{selectedIndexChange:"selectedIndexChange",focusChange:"focusChange",animationDone:"animationDone",selectedTabChange:"selectedTabChange"},exportAs:["matTabGroup"],features:[nt([{provide:aE,useExisting:n}])],ngContentSelectors:qp,decls:9,vars:8,consts:[["tabHeader",""],["tabBodyWrapper",""],["tabNode",""],[3,"indexFocused","selectFocusedIndex","selectedIndex","disableRipple","disablePagination","aria-label","aria-labelledby"],["role","tab","matTabLabelWrapper","","cdkMonitorElementFocus","",1,"mdc-tab","mat-mdc-tab","mat-focus-indicator",3,"id","mdc-tab--active","class","disabled","fitInkBarToContent"],[1,"mat-mdc-tab-body-wrapper"],["role","tabpanel",3,"id","class","content","position","animationDuration","preserveContent"],["role","tab","matTabLabelWrapper","","cdkMonitorElementFocus","",1,"mdc-tab","mat-mdc-tab","mat-focus-indicator",3,"click","cdkFocusChange","id","disabled","fitInkBarToContent"],[1,"mdc-tab__ripple"],["mat-ripple","",1,"mat-mdc-tab-ripple",3,"matRippleTrigger","matRippleDisabled"],[1,"mdc-tab__content"],[1,"mdc-tab__text-label"],[3,"cdkPortalOutlet"],["role","tabpanel",3,"_onCentered","_onCentering","_beforeCentering","id","content","position","animationDuration","preserveContent"]],template:function(r,i){if(r&1){let o=ft();Dt(),g(0,"mat-tab-header",3,0),W("indexFocused",function(a){return k(o),O(i._focusChanged(a))})("selectFocusedIndex",function(a){return k(o),O(i.selectedIndex=a)}),en(2,Cx,8,17,"div",4,ml),b(),_t(4,wx,1,0),g(5,"div",5,1),en(7,Ix,1,10,"mat-tab-body",6,ml),b()}r&2&&(pe("selectedIndex",i.selectedIndex||0)("disableRipple",i.disableRipple)("disablePagination",i.disablePagination),hl("aria-label",i.ariaLabel)("aria-labelledby",i.ariaLabelledby),I(2),tn(i._tabs),I(2),Et(i._isServer?4:-1),I(),ae("_mat-animation-noopable",i._animationsDisabled()),I(2),tn(i._tabs))},dependencies:[kx,lE,Dp,Rp,Up,Gp],styles:[`.mdc-tab{min-width:90px;padding:0 24px;display:flex;flex:1 0 auto;justify-content:center;box-sizing:border-box;border:none;outline:none;text-align:center;white-space:nowrap;cursor:pointer;z-index:1;touch-action:manipulation}.mdc-tab__content{display:flex;align-items:center;justify-content:center;height:inherit;pointer-events:none}.mdc-tab__text-label{transition:150ms color linear;display:inline-block;line-height:1;z-index:2}.mdc-tab--active .mdc-tab__text-label{transition-delay:100ms}._mat-animation-noopable .mdc-tab__text-label{transition:none}.mdc-tab-indicator{display:flex;position:absolute;top:0;left:0;justify-content:center;width:100%;height:100%;pointer-events:none;z-index:1}.mdc-tab-indicator__content{transition:var(--mat-tab-animation-duration, 250ms) transform cubic-bezier(0.4, 0, 0.2, 1);transform-origin:left;opacity:0}.mdc-tab-indicator__content--underline{align-self:flex-end;box-sizing:border-box;width:100%;border-top-style:solid}.mdc-tab-indicator--active .mdc-tab-indicator__content{opacity:1}._mat-animation-noopable .mdc-tab-indicator__content,.mdc-tab-indicator--no-transition .mdc-tab-indicator__content{transition:none}.mat-mdc-tab-ripple.mat-mdc-tab-ripple{position:absolute;top:0;left:0;bottom:0;right:0;pointer-events:none}.mat-mdc-tab{-webkit-tap-highlight-color:rgba(0,0,0,0);-webkit-font-smoothing:antialiased;-moz-osx-font-smoothing:grayscale;text-decoration:none;background:none;height:var(--mat-tab-container-height, 48px);font-family:var(--mat-tab-label-text-font, var(--mat-sys-title-small-font));font-size:var(--mat-tab-label-text-size, var(--mat-sys-title-small-size));letter-spacing:var(--mat-tab-label-text-tracking, var(--mat-sys-title-small-tracking));line-height:var(--mat-tab-label-text-line-height, var(--mat-sys-title-small-line-height));font-weight:var(--mat-tab-label-text-weight, var(--mat-sys-title-small-weight))}.mat-mdc-tab.mdc-tab{flex-grow:0}.mat-mdc-tab .mdc-tab-indicator__content--underline{border-color:var(--mat-tab-active-indicator-color, var(--mat-sys-primary));border-top-width:var(--mat-tab-active-indicator-height, 2px);border-radius:var(--mat-tab-active-indicator-shape, 0)}.mat-mdc-tab:hover .mdc-tab__text-label{color:var(--mat-tab-inactive-hover-label-text-color, var(--mat-sys-on-surface))}.mat-mdc-tab:focus .mdc-tab__text-label{color:var(--mat-tab-inactive-focus-label-text-color, var(--mat-sys-on-surface))}.mat-mdc-tab.mdc-tab--active .mdc-tab__text-label{color:var(--mat-tab-active-label-text-color, var(--mat-sys-on-surface))}.mat-mdc-tab.mdc-tab--active .mdc-tab__ripple::before,.mat-mdc-tab.mdc-tab--active .mat-ripple-element{background-color:var(--mat-tab-active-ripple-color, var(--mat-sys-on-surface))}.mat-mdc-tab.mdc-tab--active:hover .mdc-tab__text-label{color:var(--mat-tab-active-hover-label-text-color, var(--mat-sys-on-surface))}.mat-mdc-tab.mdc-tab--active:hover .mdc-tab-indicator__content--underline{border-color:var(--mat-tab-active-hover-indicator-color, var(--mat-sys-primary))}.mat-mdc-tab.mdc-tab--active:focus .mdc-tab__text-label{color:var(--mat-tab-active-focus-label-text-color, var(--mat-sys-on-surface))}.mat-mdc-tab.mdc-tab--active:focus .mdc-tab-indicator__content--underline{border-color:var(--mat-tab-active-focus-indicator-color, var(--mat-sys-primary))}.mat-mdc-tab.mat-mdc-tab-disabled{opacity:.4;pointer-events:none}.mat-mdc-tab.mat-mdc-tab-disabled .mdc-tab__content{pointer-events:none}.mat-mdc-tab.mat-mdc-tab-disabled .mdc-tab__ripple::before,.mat-mdc-tab.mat-mdc-tab-disabled .mat-ripple-element{background-color:var(--mat-tab-disabled-ripple-color, var(--mat-sys-on-surface-variant))}.mat-mdc-tab .mdc-tab__ripple::before{content:"";display:block;position:absolute;top:0;left:0;right:0;bottom:0;opacity:0;pointer-events:none;background-color:var(--mat-tab-inactive-ripple-color, var(--mat-sys-on-surface))}.mat-mdc-tab .mdc-tab__text-label{color:var(--mat-tab-inactive-label-text-color, var(--mat-sys-on-surface));display:inline-flex;align-items:center}.mat-mdc-tab .mdc-tab__content{position:relative;pointer-events:auto}.mat-mdc-tab:hover .mdc-tab__ripple::before{opacity:.04}.mat-mdc-tab.cdk-program-focused .mdc-tab__ripple::before,.mat-mdc-tab.cdk-keyboard-focused .mdc-tab__ripple::before{opacity:.12}.mat-mdc-tab .mat-ripple-element{opacity:.12;background-color:var(--mat-tab-inactive-ripple-color, var(--mat-sys-on-surface))}.mat-mdc-tab-group.mat-mdc-tab-group-stretch-tabs>.mat-mdc-tab-header .mat-mdc-tab{flex-grow:1}.mat-mdc-tab-group{display:flex;flex-direction:column;max-width:100%}.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header-pagination{background-color:var(--mat-tab-background-color)}.mat-mdc-tab-group.mat-tabs-with-background.mat-primary>.mat-mdc-tab-header .mat-mdc-tab .mdc-tab__text-label{color:var(--mat-tab-foreground-color)}.mat-mdc-tab-group.mat-tabs-with-background.mat-primary>.mat-mdc-tab-header .mdc-tab-indicator__content--underline{border-color:var(--mat-tab-foreground-color)}.mat-mdc-tab-group.mat-tabs-with-background:not(.mat-primary)>.mat-mdc-tab-header .mat-mdc-tab:not(.mdc-tab--active) .mdc-tab__text-label{color:var(--mat-tab-foreground-color)}.mat-mdc-tab-group.mat-tabs-with-background:not(.mat-primary)>.mat-mdc-tab-header .mat-mdc-tab:not(.mdc-tab--active) .mdc-tab-indicator__content--underline{border-color:var(--mat-tab-foreground-color)}.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header .mat-mdc-tab-header-pagination-chevron,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header .mat-focus-indicator::before,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header-pagination .mat-mdc-tab-header-pagination-chevron,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header-pagination .mat-focus-indicator::before{border-color:var(--mat-tab-foreground-color)}.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header .mat-ripple-element,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header .mdc-tab__ripple::before,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header-pagination .mat-ripple-element,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header-pagination .mdc-tab__ripple::before{background-color:var(--mat-tab-foreground-color)}.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header .mat-mdc-tab-header-pagination-chevron,.mat-mdc-tab-group.mat-tabs-with-background>.mat-mdc-tab-header-pagination .mat-mdc-tab-header-pagination-chevron{color:var(--mat-tab-foreground-color)}.mat-mdc-tab-group.mat-mdc-tab-group-inverted-header{flex-direction:column-reverse}.mat-mdc-tab-group.mat-mdc-tab-group-inverted-header .mdc-tab-indicator__content--underline{align-self:flex-start}.mat-mdc-tab-body-wrapper{position:relative;overflow:hidden;display:flex;transition:height 500ms cubic-bezier(0.35, 0, 0.25, 1)}.mat-mdc-tab-body-wrapper._mat-animation-noopable{transition:none !important;animation:none !important}
-`],encapsulation:2})}return n})(),Wp=class{index;tab};var uE=(()=>{class n{static \u0275fac=function(r){return new(r||n)};static \u0275mod=se({type:n});static \u0275inj=ne({imports:[We,We]})}return n})();function Px(){return window.location.pathname.startsWith("/secrets")?"/secrets":""}var gc=class n{basePath=Px();http=p(ji);toURL(t){return t.startsWith("/")?`${this.basePath}${t}`:t}request(t,e){return Me(this,null,function*(){try{return yield Vc(this.http.request(e?.method??"GET",this.toURL(t),{body:e?.body}))}catch(r){if(r instanceof Zn){let i=r.error;throw new Error(i?.error||`HTTP ${r.status}`)}throw r}})}getNamespaces(){return Me(this,null,function*(){let t=yield this.request("/api/namespaces");return Array.isArray(t.namespaces)?t.namespaces:[]})}listSecrets(t){let e=encodeURIComponent(t);return this.request(`/api/secrets?namespace=${e}`)}getSecret(t){return this.request(`/api/secrets/${encodeURIComponent(t)}`)}getSecretEvents(t){return this.request(`/api/secrets/${encodeURIComponent(t)}/events`)}getSecretYAML(t){return this.request(`/api/secrets/${encodeURIComponent(t)}/yaml`)}createSecret(t){return this.request("/api/secrets",{method:"POST",body:t})}updateSecret(t,e){return this.request(`/api/secrets/${encodeURIComponent(t)}`,{method:"PUT",body:e})}deleteSecret(t){return this.request(`/api/secrets/${encodeURIComponent(t)}`,{method:"DELETE"})}static \u0275fac=function(e){return new(e||n)};static \u0275prov=w({token:n,factory:n.\u0275fac,providedIn:"root"})};var Lx="iframe-connected",Vx="namespace-selected",jx="all-namespaces",yc=class n{namespacePoller=null;lastObservedNamespace="";onNamespaceChange=null;messageHandler=t=>{let e=t.data;if(!(!e||typeof e!="object"))switch(e.type){case Vx:typeof e.value=="string"&&this.emitNamespaceChange(e.value);break;case jx:if(Array.isArray(e.value)&&e.value.length>0){let r=this.currentNamespaceFromURL(),i=r.trim()!==""?r:String(e.value[0]??"").trim();i!==""&&this.emitNamespaceChange(i)}break;default:break}};start(t){this.stop(),this.onNamespaceChange=t,this.lastObservedNamespace=this.currentNamespaceFromURL(),window.addEventListener("message",this.messageHandler),this.notifyDashboardConnected(),this.namespacePoller=setInterval(()=>{let e=this.currentNamespaceFromURL();e===""||e===this.lastObservedNamespace||this.emitNamespaceChange(e)},300)}stop(){window.removeEventListener("message",this.messageHandler),this.namespacePoller!==null&&(clearInterval(this.namespacePoller),this.namespacePoller=null),this.onNamespaceChange=null}currentNamespaceFromURL(){let t=new URLSearchParams(window.location.search);return(t.get("namespace")??t.get("ns")??"").trim()}setCurrentNamespace(t){this.lastObservedNamespace=t.trim()}emitNamespaceChange(t){let e=t.trim();e!==""&&(this.lastObservedNamespace=e,this.onNamespaceChange&&this.onNamespaceChange(e))}notifyDashboardConnected(){let t={type:Lx};window.parent&&window.parent!==window&&window.parent.postMessage(t,"*"),window.opener&&window.opener.parent&&window.opener.parent.postMessage(t,"*")}static \u0275fac=function(e){return new(e||n)};static \u0275prov=w({token:n,factory:n.\u0275fac,providedIn:"root"})};function Zp(n,t){let e;try{e=JSON.parse(n)}catch{throw new Error(`${t} must be valid JSON.`)}if(!e||Array.isArray(e)||typeof e!="object")throw new Error(`${t} must be a JSON object.`);let r={};for(let[i,o]of Object.entries(e)){if(!i.trim())throw new Error(`${t} contains an empty key.`);if(typeof o!="string")throw new Error(`${t} values must be strings.`);r[i]=o}return r}function Yp(n){if(!n||typeof n!="object"||Array.isArray(n))return{};let t={};for(let[e,r]of Object.entries(n))typeof r=="string"&&(t[e]=r);return t}function Xp(n){let t=Yp(n),e=Object.keys(t).sort((r,i)=>r.localeCompare(i));return e.length?e.map(r=>`${r}: ${t[r]??""}`).join(`
+`],encapsulation:2})}return n})(),Wp=class{index;tab};var uE=(()=>{class n{static \u0275fac=function(r){return new(r||n)};static \u0275mod=se({type:n});static \u0275inj=ne({imports:[We,We]})}return n})();function Px(){return window.location.pathname.startsWith("/secrets")?"/secrets":""}var gc=class n{basePath=Px();http=p(ji);toURL(t){return t.startsWith("/")?`${this.basePath}${t}`:t}request(t,e){return Me(this,null,function*(){try{return yield Vc(this.http.request(e?.method??"GET",this.toURL(t),{body:e?.body}))}catch(r){if(r instanceof Zn){let i=r.error;throw new Error(i?.error||`HTTP ${r.status}`)}throw r}})}getNamespaces(){return Me(this,null,function*(){let t=yield this.request("/api/namespaces");return Array.isArray(t.namespaces)?t.namespaces:[]})}listSecrets(t){let e=encodeURIComponent(t);return this.request(`/api/secrets?namespace=${e}`)}getSecret(t){return this.request(`/api/secrets/${encodeURIComponent(t)}`)}getSecretEvents(t){return this.request(`/api/secrets/${encodeURIComponent(t)}/events`)}getSecretYAML(t){return this.request(`/api/secrets/${encodeURIComponent(t)}/yaml`)}createSecret(t){return this.request("/api/secrets",{method:"POST",body:t})}updateSecret(t,e){return this.request(`/api/secrets/${encodeURIComponent(t)}?replace=true`,{method:"PUT",body:e})}deleteSecret(t){return this.request(`/api/secrets/${encodeURIComponent(t)}`,{method:"DELETE"})}static \u0275fac=function(e){return new(e||n)};static \u0275prov=w({token:n,factory:n.\u0275fac,providedIn:"root"})};var Lx="iframe-connected",Vx="namespace-selected",jx="all-namespaces",yc=class n{namespacePoller=null;lastObservedNamespace="";onNamespaceChange=null;messageHandler=t=>{let e=t.data;if(!(!e||typeof e!="object"))switch(e.type){case Vx:typeof e.value=="string"&&this.emitNamespaceChange(e.value);break;case jx:if(Array.isArray(e.value)&&e.value.length>0){let r=this.currentNamespaceFromURL(),i=r.trim()!==""?r:String(e.value[0]??"").trim();i!==""&&this.emitNamespaceChange(i)}break;default:break}};start(t){this.stop(),this.onNamespaceChange=t,this.lastObservedNamespace=this.currentNamespaceFromURL(),window.addEventListener("message",this.messageHandler),this.notifyDashboardConnected(),this.namespacePoller=setInterval(()=>{let e=this.currentNamespaceFromURL();e===""||e===this.lastObservedNamespace||this.emitNamespaceChange(e)},300)}stop(){window.removeEventListener("message",this.messageHandler),this.namespacePoller!==null&&(clearInterval(this.namespacePoller),this.namespacePoller=null),this.onNamespaceChange=null}currentNamespaceFromURL(){let t=new URLSearchParams(window.location.search);return(t.get("namespace")??t.get("ns")??"").trim()}setCurrentNamespace(t){this.lastObservedNamespace=t.trim()}emitNamespaceChange(t){let e=t.trim();e!==""&&(this.lastObservedNamespace=e,this.onNamespaceChange&&this.onNamespaceChange(e))}notifyDashboardConnected(){let t={type:Lx};window.parent&&window.parent!==window&&window.parent.postMessage(t,"*"),window.opener&&window.opener.parent&&window.opener.parent.postMessage(t,"*")}static \u0275fac=function(e){return new(e||n)};static \u0275prov=w({token:n,factory:n.\u0275fac,providedIn:"root"})};function Zp(n,t){let e;try{e=JSON.parse(n)}catch{throw new Error(`${t} must be valid JSON.`)}if(!e||Array.isArray(e)||typeof e!="object")throw new Error(`${t} must be a JSON object.`);let r={};for(let[i,o]of Object.entries(e)){if(!i.trim())throw new Error(`${t} contains an empty key.`);if(typeof o!="string")throw new Error(`${t} values must be strings.`);r[i]=o}return r}function Yp(n){if(!n||typeof n!="object"||Array.isArray(n))return{};let t={};for(let[e,r]of Object.entries(n))typeof r=="string"&&(t[e]=r);return t}function Xp(n){let t=Yp(n),e=Object.keys(t).sort((r,i)=>r.localeCompare(i));return e.length?e.map(r=>`${r}: ${t[r]??""}`).join(`
 `):"-"}function dE(n){return Yp(n.labels)["managed-by"]??"-"}function Jp(n){let t=Object.keys(Yp(n)).sort();return t.length?t.join(", "):"-"}function eh(n,t="{}"){return!n||typeof n!="object"?t:JSON.stringify(n,null,2)}function fE(){return typeof crypto<"u"&&typeof crypto.randomUUID=="function"?crypto.randomUUID():`${Date.now()}-${Math.random().toString(36).slice(2)}`}function Rn(n){return n instanceof Error?n.message:"Unknown error"}var Bx=(n,t)=>t.value;function Hx(n,t){if(n&1&&(g(0,"option",18),D(1),b()),n&2){let e=t.$implicit;pe("value",e.value),I(),be(e.label)}}function Ux(n,t){if(n&1){let e=ft();g(0,"span",23),D(1),g(2,"button",28),W("click",function(){let i=k(e).$implicit,o=S(2);return O(o.removeFilter(i.id))}),g(3,"mat-icon"),D(4,"cancel"),b()()()}if(n&2){let e=t.$implicit,r=S(2);I(),jo(" ",r.fieldLabel(e.field),": ",e.value," "),I(),we("aria-label","Remove "+r.fieldLabel(e.field)+" filter")}}function $x(n,t){if(n&1&&(g(0,"tr")(1,"td",29),D(2),b()()),n&2){let e=S(2);I(2),Dn(" ",e.secrets.length?"No managed secrets match the current filters.":"No managed secrets found. Create one with New Secret."," ")}}function zx(n,t){if(n&1){let e=ft();g(0,"tr")(1,"td")(2,"mat-icon",30),D(3,"check_circle"),b()(),g(4,"td")(5,"button",31),W("click",function(){let i=k(e).$implicit,o=S(3);return O(o.openSecretDetails(i.name))}),D(6),b()(),g(7,"td"),D(8),b(),g(9,"td"),D(10),b(),g(11,"td",32)(12,"div",33)(13,"button",34),W("click",function(){let i=k(e).$implicit,o=S(3);return O(o.openEditDialog(i.name))}),g(14,"mat-icon"),D(15,"edit"),b()(),g(16,"button",35),W("click",function(){let i=k(e).$implicit,o=S(3);return O(o.openDeleteDialog(i.name))}),g(17,"mat-icon"),D(18,"delete"),b()()()()()}if(n&2){let e=t.$implicit,r=S(3);I(5),we("aria-label","Open details for "+e.name),I(),Dn(" ",e.name," "),I(2),be(e.type),I(2),be(r.formatDate(e.creationTimestamp))}}function Gx(n,t){if(n&1&&en(0,zx,19,4,"tr",null,Ai().trackSecret,!0),n&2){let e=S(2);tn(e.filteredSecrets)}}function Wx(n,t){if(n&1){let e=ft();g(0,"section",2)(1,"div",3),Je(2,"div",4),g(3,"div",5),D(4,"Secrets"),b(),Je(5,"div",6),g(6,"div",7)(7,"button",8),W("click",function(){k(e);let i=S();return O(i.refresh())}),D(8," Refresh "),b(),g(9,"button",9),W("click",function(){k(e);let i=S();return O(i.openCreateDialog())}),g(10,"mat-icon",10),D(11,"add"),b(),D(12," New Secret "),b()()(),Je(13,"mat-divider",11),b(),g(14,"section",12)(15,"section",13)(16,"div",14)(17,"mat-icon",15),D(18,"filter_list"),b(),g(19,"span",16),D(20,"Filter"),b(),g(21,"select",17),wn("ngModelChange",function(i){k(e);let o=S();return Qn(o.filterField,i)||(o.filterField=i),O(i)}),en(22,Hx,2,2,"option",18,Bx),b(),g(24,"input",19),wn("ngModelChange",function(i){k(e);let o=S();return Qn(o.filterValue,i)||(o.filterValue=i),O(i)}),W("keydown.enter",function(i){return k(e),S().applyFilter(),O(i.preventDefault())}),b(),g(25,"button",20),W("click",function(){k(e);let i=S();return O(i.applyFilter())}),g(26,"mat-icon"),D(27,"check"),b()(),g(28,"button",21),W("click",function(){k(e);let i=S();return O(i.clearFilters())}),g(29,"mat-icon"),D(30,"close"),b()()(),g(31,"div",22),en(32,Ux,5,3,"span",23,Ai().trackFilter,!0),b(),g(34,"div",24),D(35),b(),g(36,"table",25)(37,"thead")(38,"tr")(39,"th",26),D(40,"Status"),b(),g(41,"th",26),D(42,"Name"),b(),g(43,"th",26),D(44,"Type"),b(),g(45,"th",26),D(46,"Created at"),b(),g(47,"th",27),D(48,"Actions"),b()()(),g(49,"tbody"),_t(50,$x,3,1,"tr")(51,Gx,2,0),b()()()()}if(n&2){let e=S();I(7),pe("disabled",e.loading),I(2),pe("disabled",e.loading),I(12),Cn("ngModel",e.filterField),pe("disabled",e.loading),I(),tn(e.filterFieldOptions),I(2),Cn("ngModel",e.filterValue),pe("disabled",e.loading),I(),pe("disabled",e.loading),I(3),pe("disabled",e.loading||!e.hasActiveFilters),I(4),tn(e.filters),I(2),ae("error",e.statusError),I(),be(e.statusMessage),I(15),Et(e.filteredSecrets.length===0?50:51)}}function qx(n,t){if(n&1&&(g(0,"pre",52),D(1),b()),n&2){let e=S().$implicit;I(),be(e.value)}}function Kx(n,t){if(n&1&&D(0),n&2){let e=S().$implicit;Dn(" ",e.value," ")}}function Qx(n,t){if(n&1&&(g(0,"tr")(1,"th"),D(2),b(),g(3,"td"),_t(4,qx,2,1,"pre",52)(5,Kx,1,1),b()()),n&2){let e=t.$implicit;I(2),be(e.label),I(2),Et(e.value.includes(`
 `)?4:5)}}function Zx(n,t){if(n&1&&(g(0,"table",50)(1,"tbody"),en(2,Qx,6,2,"tr",null,Ai().trackOverview,!0),b()()),n&2){let e=S(3);I(2),tn(e.overviewItems)}}function Yx(n,t){n&1&&(g(0,"div",51),D(1,"No secret details loaded yet."),b())}function Xx(n,t){if(n&1&&(g(0,"section",49),_t(1,Zx,4,0,"table",50)(2,Yx,2,0,"div",51),b()),n&2){let e=S(2);I(),Et(e.hasOverviewData?1:2)}}function Jx(n,t){n&1&&(g(0,"tr")(1,"td",53),D(2,"No events found for this secret."),b()())}function eA(n,t){if(n&1&&(g(0,"tr")(1,"td"),D(2),b(),g(3,"td"),D(4),b(),g(5,"td"),D(6),b(),g(7,"td"),D(8),b(),g(9,"td"),D(10),b(),g(11,"td"),D(12),b()()),n&2){let e=t.$implicit,r=S(4);I(2),be(e.type||"-"),I(2),be(e.reason||"-"),I(2),be(e.message||"-"),I(2),be(e.source||"-"),I(2),be(r.formatDate(e.lastSeen)),I(2),be(e.count)}}function tA(n,t){if(n&1&&en(0,eA,13,6,"tr",null,Ai().trackEvent,!0),n&2){let e=S(3);tn(e.events)}}function nA(n,t){if(n&1&&(g(0,"section",49)(1,"table",25)(2,"thead")(3,"tr")(4,"th"),D(5,"Type"),b(),g(6,"th"),D(7,"Reason"),b(),g(8,"th"),D(9,"Message"),b(),g(10,"th"),D(11,"Source"),b(),g(12,"th"),D(13,"Last seen"),b(),g(14,"th"),D(15,"Count"),b()()(),g(16,"tbody"),_t(17,Jx,3,0,"tr")(18,tA,2,0),b()()()),n&2){let e=S(2);I(17),Et(e.events.length===0?17:18)}}function rA(n,t){if(n&1&&(g(0,"section",49)(1,"pre",54),D(2),b()()),n&2){let e=S(2);I(2),be(e.yaml||"# Empty YAML output")}}function iA(n,t){if(n&1){let e=ft();g(0,"section",2)(1,"div",3),Je(2,"div",4),g(3,"button",36),W("click",function(){k(e);let i=S();return O(i.backToList())}),g(4,"mat-icon"),D(5,"arrow_back"),b()(),g(6,"div",5),D(7,"Secret details"),b(),Je(8,"div",6),g(9,"div",7)(10,"button",8),W("click",function(){k(e);let i=S();return O(i.openEditDialog(i.activeSecret))}),D(11," Edit "),b(),g(12,"button",37),W("click",function(){k(e);let i=S();return O(i.openDeleteDialog(i.activeSecret))}),g(13,"mat-icon"),D(14,"delete"),b(),D(15," Delete "),b()()(),Je(16,"mat-divider",11),b(),g(17,"section",38)(18,"div",39)(19,"div",40)(20,"mat-icon",41),D(21,"check_circle"),b(),g(22,"div",42),D(23),b()()(),g(24,"div",43),D(25),b(),g(26,"mat-tab-group",44),W("selectedIndexChange",function(i){k(e);let o=S();return O(o.onDetailTabIndexChange(i))}),g(27,"mat-tab",45),vn(28,Xx,3,1,"ng-template",46),b(),g(29,"mat-tab",47),vn(30,nA,19,1,"ng-template",46),b(),g(31,"mat-tab",48),vn(32,rA,3,1,"ng-template",46),b()()()}if(n&2){let e=S();I(10),pe("disabled",e.loading||!e.activeSecret),I(2),pe("disabled",e.loading||!e.activeSecret),I(11),be(e.detailName),I(),ae("error",e.detailStatusError),I(),Dn(" ",e.detailStatusMessage," "),I(),pe("selectedIndex",e.detailTabIndex)}}function oA(n,t){if(n&1){let e=ft();g(0,"div",55),W("click",function(i){k(e);let o=S();return O(o.onEditorOverlayClick(i))})("keydown.enter",function(i){k(e);let o=S();return O(o.onEditorOverlayClick(i))})("keydown.space",function(i){k(e);let o=S();return O(o.onEditorOverlayClick(i))}),g(1,"div",56)(2,"div",57)(3,"h2",58),D(4),b(),g(5,"p",59),D(6),b()(),g(7,"div",60)(8,"div",61)(9,"div",62)(10,"label",63),D(11,"Name *"),b(),g(12,"input",64),wn("ngModelChange",function(i){k(e);let o=S();return Qn(o.editorName,i)||(o.editorName=i),O(i)}),b()(),g(13,"div",62)(14,"label",65),D(15,"Namespace"),b(),Je(16,"input",66),b()(),g(17,"div",62)(18,"label",67),D(19,"Type *"),b(),g(20,"select",68),wn("ngModelChange",function(i){k(e);let o=S();return Qn(o.editorType,i)||(o.editorType=i),O(i)}),g(21,"option",69),D(22,"Opaque"),b(),g(23,"option",70),D(24,"kubernetes.io/dockerconfigjson"),b()()(),g(25,"div",62)(26,"label",71),D(27,"String Data (JSON object)"),b(),g(28,"textarea",72),wn("ngModelChange",function(i){k(e);let o=S();return Qn(o.editorStringData,i)||(o.editorStringData=i),O(i)}),b(),g(29,"div",73),D(30,"UTF-8 key/value pairs."),b()(),g(31,"div",62)(32,"label",74),D(33,"Data (base64 JSON object)"),b(),g(34,"textarea",75),wn("ngModelChange",function(i){k(e);let o=S();return Qn(o.editorData,i)||(o.editorData=i),O(i)}),b(),g(35,"div",73),D(36,"Optional for binary values."),b()()(),g(37,"div",76),D(38),b(),g(39,"div",77)(40,"button",78),W("click",function(){k(e);let i=S();return O(i.saveSecret())}),D(41),b(),g(42,"button",79),W("click",function(){k(e);let i=S();return O(i.closeEditor())}),D(43,"Cancel"),b()()()()}if(n&2){let e=S();I(4),be(e.editorTitle),I(2),be(e.editorSubtitle),I(6),Cn("ngModel",e.editorName),pe("disabled",e.editorMode==="edit"),I(4),pe("value",e.namespace),I(4),Cn("ngModel",e.editorType),I(8),Cn("ngModel",e.editorStringData),I(6),Cn("ngModel",e.editorData),I(4),be(e.editorError),I(2),pe("disabled",e.loading),I(),Dn(" ",e.editorMode==="create"?"Create":"Save"," ")}}function sA(n,t){if(n&1){let e=ft();g(0,"div",55),W("click",function(i){k(e);let o=S();return O(o.onDeleteOverlayClick(i))})("keydown.enter",function(i){k(e);let o=S();return O(o.onDeleteOverlayClick(i))})("keydown.space",function(i){k(e);let o=S();return O(o.onDeleteOverlayClick(i))}),g(1,"div",80)(2,"div",57)(3,"h2",81),D(4,"Delete Secret"),b(),g(5,"p",59),D(6,"This action cannot be undone."),b()(),g(7,"div",60)(8,"p",82),D(9),b()(),g(10,"div",76),D(11),b(),g(12,"div",77)(13,"button",83),W("click",function(){k(e);let i=S();return O(i.deleteSecret())}),D(14," Delete "),b(),g(15,"button",79),W("click",function(){k(e);let i=S();return O(i.closeDeleteDialog())}),D(16,"Cancel"),b()()()()}if(n&2){let e=S();I(9),jo(' Delete secret "',e.activeSecret,'" in namespace "',e.namespace,'"? '),I(2),be(e.deleteError),I(2),pe("disabled",e.loading)}}var bc=class n{namespace="";secrets=[];loading=!1;view="list";detailTab="overview";activeSecret="";detail=null;events=[];yaml="";statusMessage="";statusError=!1;detailStatusMessage="";detailStatusError=!1;filterField="name";filterValue="";filters=[];editorOpen=!1;editorMode="create";editorTitle="New Secret";editorSubtitle="Create a managed secret in your Kubeflow profile namespace.";editorError="";editorName="";editorType="Opaque";editorStringData=`{
   "username": "example",
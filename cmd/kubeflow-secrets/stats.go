@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// handleStats serves GET /api/stats, totals across every namespace the
+// caller can use. Namespaces are listed one after another, like ?counts=true
+// on the namespace list, so a user with many profiles never fans out into
+// parallel apiserver calls. A namespace that fails to list is reported in
+// errors and left out of the totals instead of failing the whole response.
+func (s *server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user, groups, err := s.identityFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	impClient, err := s.newImpersonatedClient(user, groups)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create Kubernetes client")
+		return
+	}
+	namespaces, err := s.resolveUserNamespaces(r.Context(), user, groups, s.legacyUserFromRequest(r))
+	if err != nil {
+		status, msg := mapNamespaceResolutionError(err)
+		writeError(w, status, msg)
+		return
+	}
+
+	resp := statsResponse{
+		Namespaces: len(namespaces),
+		ByType:     make(map[corev1.SecretType]int),
+		Errors:     []statsError{},
+	}
+	for _, namespace := range namespaces {
+		secrets, err := listSecretsBySelector(r.Context(), impClient, namespace, managedLabelSelector())
+		if err != nil {
+			_, msg := mapKubeError(err, "failed to list secrets")
+			logSafef("stats failed: namespace=%q err=%v", namespace, err)
+			resp.Errors = append(resp.Errors, statsError{Namespace: namespace, Error: msg})
+			continue
+		}
+		for i := range secrets {
+			resp.add(&secrets[i])
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (resp *statsResponse) add(secret *corev1.Secret) {
+	resp.Total++
+	resp.ByType[secret.Type]++
+	resp.TotalBytes += secretDataSize(secret)
+
+	ref := &statsSecretRef{Name: secret.Name, Namespace: secret.Namespace, CreationTimestamp: secret.CreationTimestamp.Time}
+	if resp.Oldest == nil || ref.CreationTimestamp.Before(resp.Oldest.CreationTimestamp) {
+		resp.Oldest = ref
+	}
+	if resp.Newest == nil || ref.CreationTimestamp.After(resp.Newest.CreationTimestamp) {
+		resp.Newest = ref
+	}
+}
+
+// secretDataSize is the decoded size of a secret's values.
+func secretDataSize(secret *corev1.Secret) int64 {
+	var size int64
+	for _, value := range secret.Data {
+		size += int64(len(value))
+	}
+	return size
+}
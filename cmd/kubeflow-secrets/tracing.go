@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tracing follows the W3C trace-context and OTLP/HTTP JSON specifications
+// directly so no SDK is needed: one server span per request (continuing an
+// incoming traceparent), child spans for profile resolution and for every
+// apiserver round trip, exported in batches to
+// $OTEL_EXPORTER_OTLP_ENDPOINT/v1/traces. Attributes carry namespaces,
+// methods and paths, never secret data.
+
+const (
+	traceparentHeader  = "traceparent"
+	traceExportBatch   = 256
+	traceExportBuffer  = 4096
+	traceExportEvery   = 5 * time.Second
+	traceExportTimeout = 10 * time.Second
+
+	spanKindInternal = 1
+	spanKindServer   = 2
+	spanKindClient   = 3
+
+	spanStatusError = 2
+)
+
+type traceID [16]byte
+
+type spanID [8]byte
+
+type tracer struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+	spans       chan *span
+}
+
+type span struct {
+	tracer   *tracer
+	traceID  traceID
+	spanID   spanID
+	parentID spanID
+	name     string
+	kind     int
+	start    time.Time
+
+	mu       sync.Mutex
+	attrs    map[string]string
+	errorMsg string
+	endTime  time.Time
+}
+
+type spanContextKey struct{}
+
+func newTracer(endpoint, serviceName string) *tracer {
+	if endpoint == "" {
+		return nil
+	}
+	t := &tracer{
+		endpoint:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: traceExportTimeout},
+		spans:       make(chan *span, traceExportBuffer),
+	}
+	go t.exportLoop()
+	return t
+}
+
+// start opens a span as a child of the span in ctx, or as a new root when
+// there is none. A nil tracer returns a nil span; all span methods accept a
+// nil receiver so call sites need no checks.
+func (t *tracer) start(ctx context.Context, name string, kind int) (context.Context, *span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	sp := &span{tracer: t, name: name, kind: kind, start: time.Now(), attrs: make(map[string]string)}
+	if parent := spanFromContext(ctx); parent != nil {
+		sp.traceID = parent.traceID
+		sp.parentID = parent.spanID
+	} else {
+		_, _ = rand.Read(sp.traceID[:])
+	}
+	_, _ = rand.Read(sp.spanID[:])
+	return context.WithValue(ctx, spanContextKey{}, sp), sp
+}
+
+func spanFromContext(ctx context.Context) *span {
+	sp, _ := ctx.Value(spanContextKey{}).(*span)
+	return sp
+}
+
+func (sp *span) setAttr(key, value string) {
+	if sp == nil {
+		return
+	}
+	sp.mu.Lock()
+	sp.attrs[key] = value
+	sp.mu.Unlock()
+}
+
+func (sp *span) end(err error) {
+	if sp == nil {
+		return
+	}
+	if err != nil {
+		sp.setError(err.Error())
+	}
+
+	sp.mu.Lock()
+	sp.endTime = time.Now()
+	sp.mu.Unlock()
+
+	select {
+	case sp.tracer.spans <- sp:
+	default:
+		// Export is falling behind; dropping spans beats blocking requests.
+	}
+}
+
+func (sp *span) setError(msg string) {
+	sp.mu.Lock()
+	sp.errorMsg = sanitizeSingleLine(msg)
+	sp.mu.Unlock()
+}
+
+func (sp *span) traceparent() string {
+	return "00-" + hex.EncodeToString(sp.traceID[:]) + "-" + hex.EncodeToString(sp.spanID[:]) + "-01"
+}
+
+// parseTraceparent extracts the trace and parent span IDs from a W3C
+// traceparent header, e.g. 00-<32 hex>-<16 hex>-01.
+func parseTraceparent(header string) (traceID, spanID, bool) {
+	var tid traceID
+	var sid spanID
+
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return tid, sid, false
+	}
+	if n, err := hex.Decode(tid[:], []byte(parts[1])); err != nil || n != len(tid) || tid == (traceID{}) {
+		return tid, sid, false
+	}
+	if n, err := hex.Decode(sid[:], []byte(parts[2])); err != nil || n != len(sid) || sid == (spanID{}) {
+		return tid, sid, false
+	}
+	return tid, sid, true
+}
+
+func (s *server) withTracing(next http.Handler) http.Handler {
+	if s.tracer == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if tid, sid, ok := parseTraceparent(r.Header.Get(traceparentHeader)); ok {
+			ctx = context.WithValue(ctx, spanContextKey{}, &span{traceID: tid, spanID: sid})
+		}
+		ctx, sp := s.tracer.start(ctx, "HTTP "+r.Method, spanKindServer)
+		sp.setAttr("http.method", r.Method)
+		sp.setAttr("http.target", sanitizeSingleLine(r.URL.Path))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		sp.setAttr("http.status_code", strconv.Itoa(rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			sp.setError(http.StatusText(rec.status))
+		}
+		sp.end(nil)
+	})
+}
+
+// tracingRoundTripper wraps the Kubernetes client transport so every
+// apiserver call becomes a client span and carries traceparent onward.
+type tracingRoundTripper struct {
+	tracer *tracer
+	next   http.RoundTripper
+}
+
+func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, sp := t.tracer.start(req.Context(), "k8s "+req.Method, spanKindClient)
+	if sp == nil {
+		return t.next.RoundTrip(req)
+	}
+	sp.setAttr("http.method", req.Method)
+	sp.setAttr("k8s.path", req.URL.Path)
+
+	out := req.Clone(ctx)
+	out.Header.Set(traceparentHeader, sp.traceparent())
+	resp, err := t.next.RoundTrip(out)
+	if err == nil {
+		sp.setAttr("http.status_code", strconv.Itoa(resp.StatusCode))
+	}
+	sp.end(err)
+	return resp, err
+}
+
+func (t *tracer) exportLoop() {
+	ticker := time.NewTicker(traceExportEvery)
+	defer ticker.Stop()
+
+	batch := make([]*span, 0, traceExportBatch)
+	for {
+		select {
+		case sp := <-t.spans:
+			batch = append(batch, sp)
+			if len(batch) < traceExportBatch {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		t.export(batch)
+		batch = batch[:0]
+	}
+}
+
+func (t *tracer) export(batch []*span) {
+	body, err := json.Marshal(t.otlpPayload(batch))
+	if err != nil {
+		logSafef("trace export failed: encode error=%v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), traceExportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		logSafef("trace export failed: request error=%v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		logSafef("trace export failed: spans=%d err=%v", len(batch), err)
+		return
+	}
+	drainAndCloseBody(resp)
+	if resp.StatusCode >= http.StatusBadRequest {
+		logSafef("trace export failed: spans=%d status=%d", len(batch), resp.StatusCode)
+	}
+}
+
+// OTLP/HTTP JSON wire format. IDs are hex strings and timestamps decimal
+// strings, as the OTLP JSON mapping requires.
+//
+//nolint:tagliatelle // Field names are fixed by the OTLP specification.
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+//nolint:tagliatelle // Field names are fixed by the OTLP specification.
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+//nolint:tagliatelle // Field names are fixed by the OTLP specification.
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string        `json:"key"`
+	Value otlpAnyString `json:"value"`
+}
+
+type otlpAnyString struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (t *tracer) otlpPayload(batch []*span) otlpTraces {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, sp := range batch {
+		sp.mu.Lock()
+		out := otlpSpan{
+			TraceID:           hex.EncodeToString(sp.traceID[:]),
+			SpanID:            hex.EncodeToString(sp.spanID[:]),
+			Name:              sp.name,
+			Kind:              sp.kind,
+			StartTimeUnixNano: strconv.FormatInt(sp.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(sp.endTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(sp.attrs),
+		}
+		if sp.parentID != (spanID{}) {
+			out.ParentSpanID = hex.EncodeToString(sp.parentID[:])
+		}
+		if sp.errorMsg != "" {
+			out.Status = otlpStatus{Code: spanStatusError, Message: sp.errorMsg}
+		}
+		sp.mu.Unlock()
+		spans = append(spans, out)
+	}
+
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: otlpAttributes(map[string]string{"service.name": t.serviceName})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: managedByLabelValue},
+			Spans: spans,
+		}},
+	}}}
+}
+
+func otlpAttributes(attrs map[string]string) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		out = append(out, otlpKeyValue{Key: key, Value: otlpAnyString{StringValue: value}})
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// typeTransformers assemble a type's data keys from the high-level fields of
+// an upsert request, so clients need not hand-craft formats such as
+// .dockerconfigjson. Each runs before validation and returns the stringData
+// it produced; raw keys stay accepted for advanced users.
+var typeTransformers = map[corev1.SecretType]func(req secretUpsertRequest) (map[string]string, error){
+	corev1.SecretTypeDockerConfigJson: dockerConfigFromCredentials,
+}
+
+// applyTypeTransformers fills req.StringData from high-level fields. A
+// request without them is returned unchanged.
+func applyTypeTransformers(req secretUpsertRequest) (secretUpsertRequest, error) {
+	if req.DockerCredentials == nil {
+		return req, nil
+	}
+	if req.Type == "" {
+		req.Type = corev1.SecretTypeDockerConfigJson
+	}
+
+	transform, ok := typeTransformers[req.Type]
+	if !ok {
+		return req, fmt.Errorf("dockerCredentials cannot be used with secret type %q", req.Type)
+	}
+	generated, err := transform(req)
+	if err != nil {
+		return req, err
+	}
+
+	stringData := copyStringMap(req.StringData)
+	if stringData == nil {
+		stringData = make(map[string]string, len(generated))
+	}
+	for key, value := range generated {
+		_, inData := req.Data[key]
+		_, inStringData := req.StringData[key]
+		if inData || inStringData {
+			return req, fmt.Errorf("provide either dockerCredentials or a raw %q key, not both", key)
+		}
+		stringData[key] = value
+	}
+	req.StringData = stringData
+	return req, nil
+}
+
+func dockerConfigFromCredentials(req secretUpsertRequest) (map[string]string, error) {
+	creds := req.DockerCredentials
+	registry := strings.TrimSpace(creds.Registry)
+	if registry == "" {
+		return nil, errors.New("dockerCredentials.registry is required")
+	}
+	if creds.Username == "" || creds.Password == "" {
+		return nil, errors.New("dockerCredentials.username and dockerCredentials.password are required")
+	}
+
+	encoded, err := json.Marshal(dockerConfigJSON{Auths: map[string]dockerConfigEntry{
+		registry: {
+			Username: creds.Username,
+			Password: creds.Password,
+			Email:    strings.TrimSpace(creds.Email),
+			Auth:     base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password)),
+		},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{corev1.DockerConfigJsonKey: string(encoded)}, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// typeValidators check the values of well-known types beyond the presence of
+// their required keys, so a secret its consumers cannot use is rejected here
+// instead of failing later in a pod. Each gets the merged data and
+// stringData values, after typeRequiredKeys has been enforced.
+var typeValidators = map[corev1.SecretType]func(values map[string][]byte) error{
+	corev1.SecretTypeTLS:       validateTLSKeyPair,
+	corev1.SecretTypeBasicAuth: validateBasicAuth,
+	corev1.SecretTypeSSHAuth:   validateSSHAuth,
+}
+
+// validateTLSKeyPair parses tls.crt and tls.key together, which catches a
+// certificate paired with the wrong key as well as either one not being PEM.
+func validateTLSKeyPair(values map[string][]byte) error {
+	if _, err := tls.X509KeyPair(values[corev1.TLSCertKey], values[corev1.TLSPrivateKeyKey]); err != nil {
+		return fmt.Errorf("%q and %q are not a valid certificate and private key pair: %s", corev1.TLSCertKey, corev1.TLSPrivateKeyKey, strings.TrimPrefix(err.Error(), "tls: "))
+	}
+	return nil
+}
+
+// validateBasicAuth mirrors the API server's rule: either key may be
+// omitted, since some consumers take only a token as the password, but not
+// both.
+func validateBasicAuth(values map[string][]byte) error {
+	_, hasUsername := values[corev1.BasicAuthUsernameKey]
+	_, hasPassword := values[corev1.BasicAuthPasswordKey]
+	if !hasUsername && !hasPassword {
+		return fmt.Errorf("%s secret requires a %q or %q key", corev1.SecretTypeBasicAuth, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+	return nil
+}
+
+// validateSSHAuth requires ssh-privatekey to be a PEM private key. PKCS#1,
+// SEC 1 and PKCS#8 bodies are parsed too; OpenSSH and encrypted keys cannot
+// be without their passphrase or x/crypto, so for those the PEM framing is
+// checked.
+func validateSSHAuth(values map[string][]byte) error {
+	key := values[corev1.SSHAuthPrivateKey]
+	if len(strings.TrimSpace(string(key))) == 0 {
+		return fmt.Errorf("%q must not be empty", corev1.SSHAuthPrivateKey)
+	}
+	block, _ := pem.Decode(key)
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return fmt.Errorf("%q is not a PEM-encoded private key", corev1.SSHAuthPrivateKey)
+	}
+	if !parsesAsPrivateKey(block) {
+		return fmt.Errorf("%q does not parse as the %s its PEM header declares", corev1.SSHAuthPrivateKey, block.Type)
+	}
+	return nil
+}
+
+// parsesAsPrivateKey reports whether the block's body can be parsed, for
+// the PEM types the standard library knows.
+func parsesAsPrivateKey(block *pem.Block) bool {
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		_, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		_, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return len(block.Bytes) > 0
+	}
+	return err == nil
+}
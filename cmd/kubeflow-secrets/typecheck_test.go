@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testKeyPair returns a PEM certificate and key for a fresh self-signed
+// certificate.
+func testKeyPair(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestCreateTLSSecret(t *testing.T) {
+	cert, key := testKeyPair(t)
+	_, otherKey := testKeyPair(t)
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{name: "matching-pair", key: key, status: http.StatusCreated},
+		{name: "mismatched-pair", key: otherKey, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, nil)
+			client := fake.NewClientset()
+
+			body, err := json.Marshal(secretUpsertRequest{
+				Name: tt.name,
+				Type: corev1.SecretTypeTLS,
+				StringData: map[string]string{
+					corev1.TLSCertKey:       cert,
+					corev1.TLSPrivateKeyKey: tt.key,
+				},
+			})
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			rec := httptest.NewRecorder()
+			srv.handleSecretCreate(rec, newTestRequest(srv, http.MethodPost, "/api/secrets", string(body)), client, testNamespace)
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
@@ -5,42 +5,128 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type errorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+type jsonAPIErrorResponse struct {
+	Errors []jsonAPIError `json:"errors"`
+}
+
+type jsonAPIError struct {
+	Status string `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
 }
 
 type namespaceResponse struct {
-	Namespaces []string `json:"namespaces"`
+	Namespaces []string                             `json:"namespaces"`
+	Profiles   []profileNamespace                   `json:"profiles"`
+	Primary    string                               `json:"primary,omitempty"`
+	Total      int                                  `json:"total"`
+	Counts     map[string]map[corev1.SecretType]int `json:"counts,omitempty"`
+}
+
+type profileNamespace struct {
+	Namespace   string `json:"namespace"`
+	DisplayName string `json:"displayName,omitempty"`
+	Primary     bool   `json:"primary,omitempty"`
+
+	// Profile is the name of the Profile that maps to Namespace. The two
+	// differ with PROFILE_NAMESPACE_FIELD_PATH.
+	Profile string `json:"-"`
+}
+
+// resolveResponse explains namespace resolution for GET /api/admin/resolve.
+// Status and Error are what the user's own requests would get.
+type resolveResponse struct {
+	User             string             `json:"user"`
+	Groups           []string           `json:"groups"`
+	LegacyUser       string             `json:"legacyUser,omitempty"`
+	Candidates       []string           `json:"candidates"`
+	LegacyCandidates []string           `json:"legacyCandidates,omitempty"`
+	Status           int                `json:"status"`
+	Error            string             `json:"error,omitempty"`
+	Namespaces       []profileNamespace `json:"namespaces"`
+	Profiles         []resolveProfile   `json:"profiles"`
+}
+
+// resolveProfile is one Profile as seen by the matcher. Match is empty when
+// it did not match; Filtered means it matched but NAMESPACE_ALLOWLIST or
+// NAMESPACE_DENYLIST removed it.
+type resolveProfile struct {
+	Name            string   `json:"name"`
+	Namespace       string   `json:"namespace"`
+	Owner           string   `json:"owner,omitempty"`
+	OwnerCandidates []string `json:"ownerCandidates,omitempty"`
+	Match           string   `json:"match,omitempty"`
+	Filtered        bool     `json:"filtered,omitempty"`
 }
 
 type secretListItem struct {
+	UID               types.UID         `json:"uid"`
 	Name              string            `json:"name"`
 	Namespace         string            `json:"namespace"`
 	Type              corev1.SecretType `json:"type"`
 	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	Age               string            `json:"age"`
+	LastModified      *time.Time        `json:"lastModified,omitempty"`
+	Hidden            bool              `json:"hidden,omitempty"`
+	Managed           *bool             `json:"managed,omitempty"`
+	Valid             *bool             `json:"valid,omitempty"`
+	InvalidReason     string            `json:"invalidReason,omitempty"`
 }
 
 type secretListResponse struct {
-	Items []secretListItem `json:"items"`
+	Namespace string           `json:"namespace"`
+	Items     []secretListItem `json:"items"`
+	Continue  string           `json:"continue,omitempty"`
 }
 
 type secretDetailResponse struct {
+	UID               types.UID         `json:"uid"`
 	Name              string            `json:"name"`
 	Namespace         string            `json:"namespace"`
 	Type              corev1.SecretType `json:"type"`
+	ResourceVersion   string            `json:"resourceVersion"`
 	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	Age               string            `json:"age"`
 	Labels            map[string]string `json:"labels"`
 	Annotations       map[string]string `json:"annotations"`
 	Data              map[string]string `json:"data"`
 	StringData        map[string]string `json:"stringData"`
+	Immutable         bool              `json:"immutable"`
+	ImmutableNote     string            `json:"immutableNote,omitempty"`
+	ToolVersion       string            `json:"toolVersion,omitempty"`
+	Warnings          []string          `json:"warnings,omitempty"`
+	DryRun            bool              `json:"dryRun,omitempty"`
 }
 
 type secretYAMLResponse struct {
 	YAML string `json:"yaml"`
 }
 
+type cleanSecretManifest struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Metadata   cleanManifestMetadata `json:"metadata"`
+	Type       corev1.SecretType     `json:"type"`
+	Immutable  *bool                 `json:"immutable,omitempty"`
+	Data       map[string][]byte     `json:"data,omitempty"`
+}
+
+type cleanManifestMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
 type secretEventItem struct {
 	Type      string    `json:"type"`
 	Reason    string    `json:"reason"`
@@ -52,7 +138,8 @@ type secretEventItem struct {
 }
 
 type secretEventsResponse struct {
-	Items []secretEventItem `json:"items"`
+	Items     []secretEventItem `json:"items"`
+	Truncated bool              `json:"truncated,omitempty"`
 }
 
 type secretUpsertRequest struct {
@@ -63,12 +150,96 @@ type secretUpsertRequest struct {
 	StringData  map[string]string `json:"stringData"`
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
+
+	// DockerCredentials is turned into .dockerconfigjson server-side.
+	DockerCredentials *dockerCredentials `json:"dockerCredentials,omitempty"`
+}
+
+type dockerCredentials struct {
+	Registry string `json:"registry"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+type secretExportRequest struct {
+	Names      []string `json:"names"`
+	Passphrase string   `json:"passphrase"`
+}
+
+// bulkItemResult is one entry of a bulkResult. Status is the HTTP status the
+// item would have received on its own; Code is set for failures.
+type bulkItemResult struct {
+	Name     string `json:"name,omitempty"`
+	Document int    `json:"document,omitempty"`
+	Status   int    `json:"status"`
+	Code     string `json:"code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type bulkResult struct {
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []bulkItemResult `json:"items"`
+}
+
+type secretImportResponse struct {
+	Namespace string `json:"namespace"`
+	bulkResult
+}
+
+// secretBatchCreateRequest is the body of POST /api/secrets:batchCreate. Each
+// item's maps are merged over the template's.
+type secretBatchCreateRequest struct {
+	Template secretUpsertRequest     `json:"template"`
+	Items    []secretBatchCreateItem `json:"items"`
+}
+
+type secretBatchCreateItem struct {
+	Name        string            `json:"name"`
+	Data        map[string]string `json:"data"`
+	StringData  map[string]string `json:"stringData"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type secretBatchCreateResponse struct {
+	Namespace string `json:"namespace"`
+	bulkResult
+}
+
+// operationResponse is the state of an async bulk request. Result holds the
+// body, and HTTPStatus the status, the request would have answered
+// synchronously.
+type operationResponse struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Completed  int        `json:"completed"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	HTTPStatus int        `json:"httpStatus,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// secretPatchRequest is the JSON merge patch PATCH /api/secrets/{name}
+// accepts. A null value removes the key; omitted maps are left alone.
+type secretPatchRequest struct {
+	Labels      map[string]*string `json:"labels"`
+	Annotations map[string]*string `json:"annotations"`
+	Data        map[string]*string `json:"data"`
+	StringData  map[string]*string `json:"stringData"`
 }
 
 type secretUpsertResponse struct {
 	Name      string            `json:"name"`
 	Namespace string            `json:"namespace"`
 	Type      corev1.SecretType `json:"type"`
+	Warnings  []string          `json:"warnings,omitempty"`
+	DryRun    bool              `json:"dryRun,omitempty"`
 }
 
 type deleteSecretResponse struct {
@@ -77,7 +248,159 @@ type deleteSecretResponse struct {
 	Deleted   bool   `json:"deleted"`
 }
 
+type secretHiddenResponse struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Hidden    bool   `json:"hidden"`
+}
+
+type migrateLabelsResponse struct {
+	Namespace string   `json:"namespace"`
+	From      []string `json:"from"`
+	FromKey   string   `json:"fromKey,omitempty"`
+	DryRun    bool     `json:"dryRun"`
+	Matched   int      `json:"matched"`
+	bulkResult
+}
+
+type featuresResponse struct {
+	Features map[string]bool `json:"features"`
+}
+
+type statsResponse struct {
+	Namespaces int                       `json:"namespaces"`
+	Total      int                       `json:"total"`
+	ByType     map[corev1.SecretType]int `json:"byType"`
+	TotalBytes int64                     `json:"totalBytes"`
+	Oldest     *statsSecretRef           `json:"oldest,omitempty"`
+	Newest     *statsSecretRef           `json:"newest,omitempty"`
+	Errors     []statsError              `json:"errors"`
+}
+
+type statsSecretRef struct {
+	Name              string    `json:"name"`
+	Namespace         string    `json:"namespace"`
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+}
+
+type secretNameAvailableResponse struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Available bool   `json:"available"`
+}
+
+type secretsByKeyResponse struct {
+	Namespace string           `json:"namespace"`
+	Key       string           `json:"key"`
+	Items     []secretKeyMatch `json:"items"`
+}
+
+type secretKeyMatch struct {
+	Name   string            `json:"name"`
+	Type   corev1.SecretType `json:"type"`
+	Hidden bool              `json:"hidden,omitempty"`
+}
+
+type statsError struct {
+	Namespace string `json:"namespace"`
+	Error     string `json:"error"`
+}
+
+type recentSecretsResponse struct {
+	Window string             `json:"window"`
+	Since  time.Time          `json:"since"`
+	Items  []recentSecretItem `json:"items"`
+	Errors []statsError       `json:"errors"`
+}
+
+// recentSecretItem is one changed secret. Created is true when the secret
+// itself is new within the window rather than only modified.
+type recentSecretItem struct {
+	Name         string            `json:"name"`
+	Namespace    string            `json:"namespace"`
+	Type         corev1.SecretType `json:"type"`
+	LastModified time.Time         `json:"lastModified"`
+	Created      bool              `json:"created"`
+}
+
+type diagnosticsResponse struct {
+	User            string            `json:"user,omitempty"`
+	Groups          []string          `json:"groups"`
+	Namespace       string            `json:"namespace,omitempty"`
+	Checks          []diagnosticCheck `json:"checks"`
+	SecretVerbs     []string          `json:"secretVerbs,omitempty"`
+	RulesIncomplete bool              `json:"rulesIncomplete,omitempty"`
+}
+
+type diagnosticCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type readyzResponse struct {
+	ControlPlane   string `json:"controlPlane"`
+	CircuitBreaker string `json:"circuitBreaker"`
+}
+
+type namespacePolicyResponse struct {
+	Namespace       string                         `json:"namespace"`
+	AllowedTypes    []corev1.SecretType            `json:"allowedTypes"`
+	DefaultType     corev1.SecretType              `json:"defaultType"`
+	MaxPayloadBytes int64                          `json:"maxPayloadBytes"`
+	RequiredKeys    map[corev1.SecretType][]string `json:"requiredKeys"`
+	RequiredLabels  []string                       `json:"requiredLabels"`
+	// AllowedKeys is only populated when STRICT_TYPE_KEYS is on.
+	AllowedKeys map[corev1.SecretType][]string `json:"allowedKeys,omitempty"`
+	// AllowedRegistries is only populated when ALLOWED_REGISTRIES is set.
+	AllowedRegistries []string               `json:"allowedRegistries,omitempty"`
+	Quota             namespaceQuotaResponse `json:"quota"`
+}
+
+type namespaceQuotaResponse struct {
+	// PrecheckEnabled reports QUOTA_CHECK_ENABLED: creates that would exceed
+	// a limit are refused before reaching the apiserver.
+	PrecheckEnabled bool               `json:"precheckEnabled"`
+	Limits          []secretQuotaLimit `json:"limits"`
+}
+
+type secretQuotaLimit struct {
+	Quota    string              `json:"quota"`
+	Resource corev1.ResourceName `json:"resource"`
+	Hard     resource.Quantity   `json:"hard"`
+	Used     resource.Quantity   `json:"used"`
+}
+
+type gcResponse struct {
+	Namespace string `json:"namespace"`
+	Deleted   int    `json:"deleted"`
+}
+
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
 }
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+type registryTestResult struct {
+	Registry   string `json:"registry"`
+	OK         bool   `json:"ok"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type registryTestResponse struct {
+	Name      string               `json:"name"`
+	Namespace string               `json:"namespace"`
+	Results   []registryTestResult `json:"results"`
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// uiFallbackPage replaces the SPA on first load when the API it depends on
+// would fail, so users get an explanation instead of a blank page.
+var uiFallbackPage = template.Must(template.New("fallback").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Kubeflow Secrets - {{.Title}}</title>
+<style>
+body{font-family:Roboto,Helvetica,Arial,sans-serif;background:#fafafa;color:rgba(0,0,0,.87);margin:0}
+main{max-width:40rem;margin:4rem auto;padding:0 1.5rem}
+h1{font-size:1.5rem;font-weight:500}
+p{line-height:1.5}
+.hint{color:rgba(0,0,0,.6)}
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+<p class="hint">{{.Hint}}</p>
+<p><a href="">Reload</a></p>
+</main>
+</body>
+</html>
+`))
+
+type uiFallbackData struct {
+	Title   string
+	Message string
+	Hint    string
+}
+
+// withUIFallback checks, for navigations to the SPA entry point only, what
+// the SPA's first API calls would run into: an open circuit breaker, missing
+// identity headers or a failed Profile resolution. Any of those renders
+// uiFallbackPage with the matching status; otherwise next serves the SPA.
+// This costs one namespace resolution per page load, as the SPA's own first
+// request does.
+func (s *server) withUIFallback(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isUINavigation(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if status, data, failed := s.uiProblem(r); failed {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(status)
+			if err := uiFallbackPage.Execute(w, data); err != nil {
+				logSafef("ui fallback render failed: err=%v", err)
+			}
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isUINavigation(r *http.Request) bool {
+	if r.Method != http.MethodGet || (r.URL.Path != "/" && r.URL.Path != "/index.html") {
+		return false
+	}
+	accept := r.Header.Get("Accept")
+	return accept == "" || strings.Contains(accept, "text/html")
+}
+
+func (s *server) uiProblem(r *http.Request) (int, uiFallbackData, bool) {
+	unavailable := uiFallbackData{
+		Title:   "Secrets API unavailable",
+		Message: "The server cannot reach the Kubernetes API right now.",
+		Hint:    "It retries on its own; reload this page in a minute. If this persists, ask your administrator to check the kubeflow-secrets deployment.",
+	}
+	if s.adminBreaker.state() == breakerStateOpen {
+		return http.StatusServiceUnavailable, unavailable, true
+	}
+
+	user, groups, err := s.identityFromRequest(r)
+	if err != nil {
+		return http.StatusUnauthorized, uiFallbackData{
+			Title:   "Not signed in",
+			Message: err.Error() + ".",
+			Hint:    "Open Kubeflow Secrets through the Kubeflow dashboard so your identity is forwarded.",
+		}, true
+	}
+
+	_, err = s.resolveUserProfiles(r.Context(), user, groups, s.legacyUserFromRequest(r))
+	if err == nil {
+		return 0, uiFallbackData{}, false
+	}
+	status, msg := mapNamespaceResolutionError(err)
+	switch {
+	case errors.Is(err, errProfileNotFound), errors.Is(err, errNamespacesFiltered):
+		return status, uiFallbackData{
+			Title:   "No namespace available",
+			Message: "Signed in as " + user + ", but " + msg + ".",
+			Hint:    "Ask your administrator for a Kubeflow Profile or to be added as a contributor to one.",
+		}, true
+	case status == http.StatusForbidden:
+		return status, uiFallbackData{
+			Title:   "Permission problem",
+			Message: "The server is not allowed to look up Kubeflow Profiles.",
+			Hint:    "Ask your administrator to check the RBAC of the kubeflow-secrets service account.",
+		}, true
+	default:
+		unavailable.Message = msg + "."
+		return http.StatusServiceUnavailable, unavailable, true
+	}
+}
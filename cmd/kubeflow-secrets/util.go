@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func envOrDefault(key, fallback string) string {
@@ -13,6 +15,52 @@ func envOrDefault(key, fallback string) string {
 	return fallback
 }
 
+func envList(key string) []string {
+	return normalizeGroups([]string{os.Getenv(key)})
+}
+
+func envBool(key string, fallback bool) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func envInt(key string, fallback int64) (int64, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if parsed < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", key)
+	}
+	return parsed, nil
+}
+
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if parsed <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive", key)
+	}
+	return parsed, nil
+}
+
 func limitStrings(values []string, limit int) []string {
 	if len(values) <= limit {
 		return values
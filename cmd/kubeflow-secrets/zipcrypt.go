@@ -0,0 +1,134 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // WinZip AES mandates HMAC-SHA1 for key derivation and authentication.
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// WinZip AE-2 encryption (https://www.winzip.com/en/support/aes-encryption/),
+// which archive/zip does not implement. 7-Zip, WinZip and The Unarchiver
+// read it; Info-ZIP `unzip` does not.
+const (
+	zipMethodWinZipAES    = 99
+	zipExtraWinZipAES     = 0x9901
+	zipFlagEncrypted      = 0x1
+	winZipAESVersionAE2   = 2
+	winZipAESStrength256  = 3
+	winZipAESKeySize      = 32
+	winZipAESSaltSize     = 16
+	winZipAESVerifierSize = 2
+	winZipAESMACSize      = 10
+	winZipAESIterations   = 1000
+)
+
+// writeEncryptedZipEntry deflates content and stores it in zw as an AES-256
+// encrypted entry. Each entry gets its own salt, so keys are never reused.
+func writeEncryptedZipEntry(zw *zip.Writer, name string, content []byte, passphrase string, modified time.Time) error {
+	var compressed bytes.Buffer
+	fw, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(content); err != nil {
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+
+	salt := make([]byte, winZipAESSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	keys, err := pbkdf2.Key(sha1.New, passphrase, salt, winZipAESIterations, 2*winZipAESKeySize+winZipAESVerifierSize)
+	if err != nil {
+		return err
+	}
+	encKey := keys[:winZipAESKeySize]
+	macKey := keys[winZipAESKeySize : 2*winZipAESKeySize]
+	verifier := keys[2*winZipAESKeySize:]
+
+	ciphertext, err := winZipAESCTR(encKey, compressed.Bytes())
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha1.New, macKey)
+	mac.Write(ciphertext)
+	authCode := mac.Sum(nil)[:winZipAESMACSize]
+
+	extra := make([]byte, 0, 11)
+	extra = binary.LittleEndian.AppendUint16(extra, zipExtraWinZipAES)
+	extra = binary.LittleEndian.AppendUint16(extra, 7)
+	extra = binary.LittleEndian.AppendUint16(extra, winZipAESVersionAE2)
+	extra = append(extra, 'A', 'E', winZipAESStrength256)
+	extra = binary.LittleEndian.AppendUint16(extra, zip.Deflate)
+
+	payloadSize := len(salt) + len(verifier) + len(ciphertext) + len(authCode)
+	dosDate, dosTime := msDosTime(modified)
+	header := &zip.FileHeader{
+		Name:               name,
+		Method:             zipMethodWinZipAES,
+		Flags:              zipFlagEncrypted,
+		Modified:           modified,
+		ModifiedDate:       dosDate,
+		ModifiedTime:       dosTime,
+		Extra:              extra,
+		CompressedSize64:   uint64(payloadSize),
+		UncompressedSize64: uint64(len(content)),
+		// AE-2 leaves CRC32 at zero; the HMAC authenticates the data instead.
+	}
+	w, err := zw.CreateRaw(header)
+	if err != nil {
+		return err
+	}
+	for _, part := range [][]byte{salt, verifier, ciphertext, authCode} {
+		if _, err := w.Write(part); err != nil {
+			return fmt.Errorf("write zip entry %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// winZipAESCTR is AES in the CTR variant WinZip uses: a 16-byte little-endian
+// counter starting at 1, unlike crypto/cipher's big-endian CTR.
+func winZipAESCTR(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(plaintext))
+	var counter, stream [aes.BlockSize]byte
+	for offset := 0; offset < len(plaintext); offset += aes.BlockSize {
+		for i := range counter {
+			counter[i]++
+			if counter[i] != 0 {
+				break
+			}
+		}
+		block.Encrypt(stream[:], counter[:])
+		end := min(offset+aes.BlockSize, len(plaintext))
+		for i := offset; i < end; i++ {
+			out[i] = plaintext[i] ^ stream[i-offset]
+		}
+	}
+	return out, nil
+}
+
+// msDosTime fills the legacy header timestamp, which CreateRaw, unlike
+// CreateHeader, does not derive from Modified.
+func msDosTime(t time.Time) (uint16, uint16) {
+	t = t.UTC()
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	clock := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, clock
+}
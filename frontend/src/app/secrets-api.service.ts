@@ -74,8 +74,10 @@ export class SecretsAPIService {
     return this.request('/api/secrets', { method: 'POST', body: payload });
   }
 
+  // The edit form always sends the full key set, so keys removed in the form
+  // must be deleted rather than kept by the server's default merge.
   updateSecret(name: string, payload: SecretUpsertRequest): Promise<void> {
-    return this.request(`/api/secrets/${encodeURIComponent(name)}`, {
+    return this.request(`/api/secrets/${encodeURIComponent(name)}?replace=true`, {
       method: 'PUT',
       body: payload,
     });